@@ -0,0 +1,7 @@
+package defaults
+
+import "time"
+
+// FlowTimeout defines the default maximum time that output is blocked waiting
+// for XON when the output buffer fills while paused (see flow.Block).
+const FlowTimeout = 10 * time.Second
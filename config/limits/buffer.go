@@ -19,6 +19,6 @@ const BytesPerBuffer = MaxBytesPerRune * RunesPerLine // Max bytes per 1 line
 // Note that this probably isn't large enough for every possible valid key code
 // byte sequence, but we need to place a reasonable upper bound on this space.
 //
-// Using escape.go as reference (github.com/ardnew/embedit/seq/ansi), the widest
-// sequence recognized appears to be 6 bytes.
-const MaxBytesPerKey = 2 * MaxBytesPerRune // TBD: Is there a _correct_ value?
+// The widest sequence recognized is an SGR mouse report, which is 16 bytes for
+// a wheel event at column and row 1000 (e.g., ESC [ < 64 ; 1000 ; 1000 M).
+const MaxBytesPerKey = 4 * MaxBytesPerRune // TBD: Is there a _correct_ value?
@@ -0,0 +1,12 @@
+package limits
+
+// CellsPerLine defines the maximum number of display cells modeled for a line of
+// input, which is enough for every rune in a line to occupy two columns.
+//
+// Cells beyond this limit are not modeled, and are always redrawn.
+const CellsPerLine = 2 * RunesPerLine
+
+// RunesPerPrompt defines the maximum number of runes in the user input prompt,
+// including the runes of any escape sequences and newlines it contains. Longer
+// prompts are truncated.
+const RunesPerPrompt = 128
@@ -2,9 +2,15 @@ package embedit
 
 import (
 	"io"
+	"time"
 
+	"github.com/ardnew/embedit/seq/ansi"
+	"github.com/ardnew/embedit/seq/eol"
 	"github.com/ardnew/embedit/terminal"
+	"github.com/ardnew/embedit/terminal/clipboard/paste"
 	"github.com/ardnew/embedit/terminal/cursor"
+	"github.com/ardnew/embedit/terminal/display"
+	"github.com/ardnew/embedit/terminal/flow"
 	"github.com/ardnew/embedit/terminal/line"
 )
 
@@ -23,11 +29,31 @@ type Embedit struct {
 
 // Config defines the configuration parameters of an Embedit.
 type Config struct {
-	RW        io.ReadWriter
-	Prompt    []rune
-	Width     int
-	Height    int
-	AutoFlush bool
+	RW          io.ReadWriter
+	Prompt      []rune           // May contain newlines to span multiple rows
+	RightPrompt []rune           // Drawn at the right edge of the first row
+	Prompter    display.Prompter // Generates Prompt each time it is drawn
+	Width       int
+	Height      int
+	AutoFlush   bool
+	Mouse       bool                // Enable mouse reporting (xterm SGR 1006)
+	InputEOL    eol.Mode            // End-of-line received from RW
+	OutputEOL   eol.Mode            // End-of-line written to RW (eol.Auto: CRLF)
+	FlowControl bool                // Enable software (XON/XOFF) flow control
+	FlowPolicy  flow.Policy         // Behavior when output fills while paused
+	FlowTimeout time.Duration       // Maximum wait for XON with flow.Block (0: default)
+	Paste       paste.Policy        // Handling of text received by bracketed paste
+	Scroll      bool                // Keep input on one row, scrolled horizontally
+	Dumb        bool                // Edit without control sequences (TERM=dumb)
+	Cooked      bool                // Read plain lines even if RW is a terminal
+	Caps        display.Capability  // Optional control functions (e.g., ICH, DCH)
+	Depth       ansi.Depth          // Color depth, to which styles are downgraded
+	Highlighter display.Highlighter // Styles the text of the line as it is typed
+	Spans       []display.Span      // Storage for spans filled by Highlighter
+	Suggest     bool                // Suggest completions from history
+	Suggester   display.Suggester   // Source of suggestions instead of history
+	Probe       time.Duration       // Discover display size, if positive
+	Resync      bool                // Resynchronize cursor on ClearScreen (Ctrl+L)
 }
 
 // New allocates a new Embedit and returns a pointer to that object.
@@ -36,9 +62,36 @@ type Config struct {
 func New() Embedit { return Embedit{} }
 
 // Configure initializes the Embedit configuration.
+//
+// Plain lines are read, as with Config.Cooked, if RW has a file descriptor
+// (i.e., method Fd() uintptr) that is not connected to a terminal, e.g., if
+// standard input is redirected from a file or a pipe.
 func (e *Embedit) Configure(config Config) *Embedit {
 	e.valid = false
 	_ = e.term.Configure(config.RW, config.Prompt, config.Width, config.Height, config.AutoFlush)
+	_ = e.term.EnableMouse(config.Mouse)
+	e.term.SetEOL(config.InputEOL, config.OutputEOL)
+	_ = e.term.EnableFlowControl(config.FlowControl, config.FlowPolicy)
+	e.term.SetFlowTimeout(config.FlowTimeout)
+	e.term.SetPastePolicy(config.Paste)
+	_ = e.term.EnableHorizontalScroll(config.Scroll)
+	_ = e.term.EnableDumb(config.Dumb)
+	cooked := config.Cooked || !isTerminal(config.RW)
+	_ = e.term.EnableCooked(cooked)
+	e.term.SetPrompter(config.Prompter)
+	e.term.SetRightPrompt(config.RightPrompt)
+	e.term.SetCapabilities(config.Caps)
+	e.term.SetDepth(config.Depth)
+	e.term.SetHighlighter(config.Highlighter, config.Spans)
+	_ = e.term.EnableSuggestions(config.Suggest)
+	if config.Suggester != nil {
+		e.term.SetSuggester(config.Suggester)
+	}
+	_ = e.term.EnableResync(config.Resync)
+	if config.Probe > 0 && !cooked {
+		// Width and Height are retained if the terminal does not reply.
+		_, _, _ = e.term.ProbeSize(config.Probe)
+	}
 	return e.init()
 }
 
@@ -0,0 +1,68 @@
+package embedit
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// file is an io.ReadWriter that reads from an *os.File, and has its file
+// descriptor.
+type file struct {
+	*os.File
+	strings.Builder
+}
+
+func (f *file) Read(p []byte) (int, error)  { return f.File.Read(p) }
+func (f *file) Write(p []byte) (int, error) { return f.Builder.Write(p) }
+
+func TestEmbedit_ConfigureCooked(t *testing.T) {
+	t.Parallel()
+	type rw struct {
+		io.Reader
+		strings.Builder
+	}
+	for name, tt := range map[string]struct {
+		fd     bool // RW is a pipe with a file descriptor
+		cooked bool
+		want   string
+	}{
+		"pipe":          {fd: true, want: ""},
+		"no-fd":         {want: "> abc\r\r\n"},
+		"no-fd-cooked":  {cooked: true, want: ""},
+		"pipe-override": {fd: true, cooked: true, want: ""},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			r, w, err := os.Pipe()
+			if err != nil {
+				t.Fatalf("os.Pipe() error = %v", err)
+			}
+			t.Cleanup(func() { r.Close() })
+			_, _ = w.WriteString("abc\r\n")
+			w.Close()
+			var out *strings.Builder
+			var e Embedit
+			if tt.fd {
+				f := &file{File: r}
+				out = &f.Builder
+				e.Configure(Config{RW: f, Width: 80, Height: 24, Cooked: tt.cooked})
+			} else {
+				f := &rw{Reader: r}
+				out = &f.Builder
+				e.Configure(Config{RW: f, Width: 80, Height: 24, Cooked: tt.cooked})
+			}
+			e.Terminal().EnableBracketedPaste(false)
+			if err := e.Terminal().ReadLine(); err != nil {
+				t.Fatalf("ReadLine() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, out.String()); diff != "" {
+				t.Errorf("ReadLine() output mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
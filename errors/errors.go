@@ -9,6 +9,7 @@ package errors
 // WriteOverflow
 // ReadOverflow
 // PasteIndicator
+// Timeout
 
 type (
 	InvalidReceiver struct{}
@@ -17,6 +18,7 @@ type (
 	WriteOverflow   struct{}
 	ReadOverflow    struct{}
 	PasteIndicator  struct{}
+	Timeout         struct{}
 )
 
 var (
@@ -26,6 +28,7 @@ var (
 	ErrWriteOverflow   WriteOverflow
 	ErrReadOverflow    ReadOverflow
 	ErrPasteIndicator  PasteIndicator
+	ErrTimeout         Timeout
 )
 
 func (e *InvalidReceiver) Error() string {
@@ -51,3 +54,7 @@ func (e *ReadOverflow) Error() string {
 func (e *PasteIndicator) Error() string {
 	return "paste indicator"
 }
+
+func (e *Timeout) Error() string {
+	return "timeout"
+}
@@ -13,20 +13,34 @@ const pkgName = "stdio"
 // Static storage for our main object.
 var em embedit.Embedit
 
-// A simple io.ReadWriter used in the embedit.Config object.
-var rw = &struct {
+// stdio is a simple io.ReadWriter used in the embedit.Config object. Its file
+// descriptor is that of stdin, so that plain lines are read if stdin is not a
+// terminal, e.g., redirected from a file.
+type stdio struct {
 	io.Reader
 	io.Writer
-}{os.Stdin, os.Stdout}
+}
+
+// Fd returns the file descriptor of stdin.
+func (stdio) Fd() uintptr { return os.Stdin.Fd() }
+
+var rw = &stdio{os.Stdin, os.Stdout}
 
 func main() {
 	f := sys.MakeFdio(int(os.Stdin.Fd()))
-	if !f.Valid() || !f.Raw() {
-		panic(os.ErrInvalid)
+	width, height, ok := f.Size()
+	if !ok {
+		width, height = 80, 24
+	}
+	if f.Raw() {
+		defer f.Restore()
+		// Resize the display each time the terminal window is resized.
+		w := f.WatchSize(os.Stdin)
+		defer w.Stop()
+		rw.Reader = w
 	}
-	defer f.Restore()
 
-	em.Configure(embedit.Config{RW: rw, Width: 80, Height: 24})
+	em.Configure(embedit.Config{RW: rw, Width: width, Height: height})
 	for {
 		if em.Terminal().ReadLine() != nil {
 			return
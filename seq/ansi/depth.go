@@ -0,0 +1,146 @@
+package ansi
+
+// Depth is the number of colors a terminal is able to display, in order of
+// decreasing capability.
+//
+// The zero value is DepthTrue, such that styles are written unmodified unless a
+// lesser Depth is configured.
+type Depth uint8
+
+// Constant values of enumerated type Depth.
+const (
+	DepthTrue Depth = iota // 24-bit RGB colors ("truecolor")
+	Depth256               // 256 indexed colors
+	Depth16                // 16 basic colors
+	DepthMono              // No colors; attributes only (e.g., VT100, VT220)
+	DepthNone              // No SGR sequences at all (e.g., dumb terminals)
+)
+
+// palette contains the RGB components of the 16 basic colors, using the
+// default values of xterm.
+var palette = [16][3]uint8{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// cube contains the component values of the 6×6×6 color cube of the 256-color
+// palette (indices 16–231).
+var cube = [6]uint8{0, 95, 135, 175, 215, 255}
+
+// Downgrade returns s with its colors replaced by the nearest colors that can be
+// displayed with depth d.
+//
+// RGB colors are replaced with indexed colors, and indexed colors with basic
+// colors. Without colors (DepthMono or DepthNone), a foreground color is
+// replaced with bold, and a background color with reverse video, so that the
+// styled glyphs remain distinguishable.
+func (s Style) Downgrade(d Depth) Style {
+	if d >= DepthMono {
+		if s.Fg != ColorDefault {
+			s.Attr |= AttrBold
+		}
+		if s.Bg != ColorDefault {
+			s.Attr |= AttrReverse
+		}
+		s.Fg, s.Bg = ColorDefault, ColorDefault
+		return s
+	}
+	s.Fg = s.Fg.Downgrade(d)
+	s.Bg = s.Bg.Downgrade(d)
+	return s
+}
+
+// Downgrade returns the nearest color to c that can be displayed with depth d,
+// or ColorDefault if d has no colors.
+func (c Color) Downgrade(d Depth) Color {
+	switch {
+	case d >= DepthMono:
+		return ColorDefault
+	case d == Depth256 && c&colorKind == colorRGB:
+		r, g, b := c.rgb()
+		return Indexed(nearestIndexed(r, g, b))
+	case d == Depth16 && c&colorKind == colorIndexed && c&0xFF < 16:
+		return colorBasic | c&0xFF
+	case d == Depth16 && c&colorKind != colorBasic && c != ColorDefault:
+		r, g, b := c.rgb()
+		return colorBasic | Color(nearestBasic(r, g, b))
+	}
+	return c
+}
+
+// rgb returns the red, green, and blue components of c.
+func (c Color) rgb() (r, g, b uint8) {
+	n := uint8(c)
+	switch c & colorKind {
+	case colorRGB:
+		return uint8(c >> 16), uint8(c >> 8), n
+	case colorBasic:
+		p := palette[n&0xF]
+		return p[0], p[1], p[2]
+	case colorIndexed:
+		switch {
+		case n < 16:
+			p := palette[n]
+			return p[0], p[1], p[2]
+		case n < 232:
+			n -= 16
+			return cube[n/36], cube[n/6%6], cube[n%6]
+		}
+		v := 8 + 10*(n-232)
+		return v, v, v
+	}
+	return
+}
+
+// nearestIndexed returns the index of the color in the color cube or grayscale
+// ramp of the 256-color palette nearest to the given RGB color.
+func nearestIndexed(r, g, b uint8) uint8 {
+	qr, qg, qb := cubeIndex(r), cubeIndex(g), cubeIndex(b)
+	cr, cg, cb := cube[qr], cube[qg], cube[qb]
+	ci := 16 + 36*qr + 6*qg + qb
+	if cr == r && cg == g && cb == b {
+		return ci
+	}
+	// Compare with the nearest gray.
+	avg := (int(r) + int(g) + int(b)) / 3
+	gi := 23
+	if avg < 238 {
+		gi = (avg - 3) / 10
+	}
+	v := uint8(8 + 10*gi)
+	if distance(v, v, v, r, g, b) < distance(cr, cg, cb, r, g, b) {
+		return uint8(232 + gi)
+	}
+	return ci
+}
+
+// cubeIndex returns the index of the component value in cube nearest to v.
+func cubeIndex(v uint8) uint8 {
+	switch {
+	case v < 48:
+		return 0
+	case v < 115:
+		return 1
+	}
+	return (v - 35) / 40
+}
+
+// nearestBasic returns the index of the basic color nearest to the given RGB
+// color.
+func nearestBasic(r, g, b uint8) (n uint8) {
+	min := -1
+	for i, p := range palette {
+		if d := distance(p[0], p[1], p[2], r, g, b); min < 0 || d < min {
+			min, n = d, uint8(i)
+		}
+	}
+	return
+}
+
+// distance returns the squared Euclidean distance between two RGB colors.
+func distance(r1, g1, b1, r2, g2, b2 uint8) int {
+	dr, dg, db := int(r1)-int(r2), int(g1)-int(g2), int(b1)-int(b2)
+	return dr*dr + dg*dg + db*db
+}
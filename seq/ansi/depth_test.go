@@ -0,0 +1,63 @@
+package ansi
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStyle_Downgrade(t *testing.T) {
+	t.Parallel()
+	for name, tt := range map[string]struct {
+		style Style
+		depth Depth
+		want  Style
+	}{
+		"true-unchanged": {style: Style{Fg: RGB(1, 2, 3)}, depth: DepthTrue, want: Style{Fg: RGB(1, 2, 3)}},
+		"256-cube":       {style: Style{Fg: RGB(255, 135, 0)}, depth: Depth256, want: Style{Fg: Indexed(208)}},
+		"256-gray":       {style: Style{Bg: RGB(128, 128, 130)}, depth: Depth256, want: Style{Bg: Indexed(244)}},
+		"256-indexed":    {style: Style{Fg: Indexed(100)}, depth: Depth256, want: Style{Fg: Indexed(100)}},
+		"16-rgb":         {style: Style{Fg: RGB(250, 10, 10)}, depth: Depth16, want: Style{Fg: BrightRed}},
+		"16-indexed-low": {style: Style{Fg: Indexed(4)}, depth: Depth16, want: Style{Fg: Blue}},
+		"16-cube":        {style: Style{Fg: Indexed(46)}, depth: Depth16, want: Style{Fg: BrightGreen}},
+		"16-gray":        {style: Style{Bg: Indexed(244)}, depth: Depth16, want: Style{Bg: BrightBlack}},
+		"16-basic":       {style: Style{Fg: Cyan}, depth: Depth16, want: Style{Fg: Cyan}},
+		"mono-fg":        {style: Style{Fg: Red}.Underline(), depth: DepthMono, want: Style{Attr: AttrBold | AttrUnderline}},
+		"mono-bg":        {style: Style{Bg: RGB(0, 0, 0)}, depth: DepthMono, want: Style{Attr: AttrReverse}},
+		"mono-default":   {style: Style{}, depth: DepthMono, want: Style{}},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if diff := cmp.Diff(tt.want, tt.style.Downgrade(tt.depth)); len(diff) > 0 {
+				t.Errorf("diff (-want +got):%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestSGR_Depth(t *testing.T) {
+	t.Parallel()
+	for name, tt := range map[string]struct {
+		depth Depth
+		want  string
+	}{
+		"true": {depth: DepthTrue, want: "\x1b[0;38;2;255;0;0m"},
+		"256":  {depth: Depth256, want: "\x1b[0;38;5;196m"},
+		"16":   {depth: Depth16, want: "\x1b[0;91m"},
+		"mono": {depth: DepthMono, want: "\x1b[0;1m"},
+		"none": {depth: DepthNone, want: ""},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var g SGR
+			g.SetDepth(tt.depth)
+			p := make([]rune, 32)
+			n := g.Runes(p, Style{}.Foreground(RGB(255, 0, 0)))
+			if diff := cmp.Diff(tt.want, string(p[:n])); len(diff) > 0 {
+				t.Errorf("diff (-want +got):%s\n", diff)
+			}
+		})
+	}
+}
@@ -8,5 +8,23 @@ var (
 	CLS = []byte{Escape, '[', '2', 'J'}           // Clear screen
 	XY0 = []byte{Escape, '[', 'H'}                // Set cursor X=0 Y=0
 	KIL = []byte{Escape, '[', 'K'}                // Clear line right
+	KIB = []byte{Escape, '[', 'J'}                // Clear screen right and below
 	DEL = []byte{' ', Escape, '[', 'D'}           // Delete next rune
+	DSR = []byte{Escape, '[', '6', 'n'}           // Request cursor position
+	SCP = []byte{Escape, '7'}                     // Save cursor position
+	RCP = []byte{Escape, '8'}                     // Restore cursor position
+	// Move cursor to bottom-right, limited by the display size
+	FAR = []byte{Escape, '[', '9', '9', '9', ';', '9', '9', '9', 'H'}
+)
+
+// Private mode escape sequences (DECSET, DECRST).
+var (
+	// Enable mouse button reporting (1000) with SGR extended coordinates (1006).
+	MSE = []byte{Escape, '[', '?', '1', '0', '0', '0', ';', '1', '0', '0', '6', 'h'}
+	// Disable SGR extended coordinates (1006) and mouse button reporting (1000).
+	MSD = []byte{Escape, '[', '?', '1', '0', '0', '6', ';', '1', '0', '0', '0', 'l'}
+	// Enable bracketed paste (2004).
+	BPE = []byte{Escape, '[', '?', '2', '0', '0', '4', 'h'}
+	// Disable bracketed paste (2004).
+	BPD = []byte{Escape, '[', '?', '2', '0', '0', '4', 'l'}
 )
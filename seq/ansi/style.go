@@ -0,0 +1,267 @@
+package ansi
+
+import (
+	"io"
+
+	"github.com/ardnew/embedit/errors"
+	"github.com/ardnew/embedit/seq/ascii"
+	"github.com/ardnew/embedit/terminal/wire"
+)
+
+// Color is a foreground or background color of a Style.
+//
+// The zero value is the terminal's default color. Other colors are one of the
+// 16 basic colors, one of 256 indexed colors, or a 24-bit RGB color.
+type Color uint32
+
+// Kinds of Color, stored in the most significant byte of a Color.
+const (
+	colorBasic   Color = (iota + 1) << 24 // One of 16 basic colors
+	colorIndexed                          // One of 256 indexed colors
+	colorRGB                              // 24-bit RGB color ("truecolor")
+	colorKind    Color = 0xFF << 24
+)
+
+// ColorDefault is the terminal's default foreground or background color.
+const ColorDefault Color = 0
+
+// Constant values of the 16 basic colors of type Color.
+const (
+	Black Color = colorBasic + iota
+	Red
+	Green
+	Yellow
+	Blue
+	Magenta
+	Cyan
+	White
+	BrightBlack
+	BrightRed
+	BrightGreen
+	BrightYellow
+	BrightBlue
+	BrightMagenta
+	BrightCyan
+	BrightWhite
+)
+
+// Indexed returns the indexed Color n of the 256-color palette. Indices 0–15
+// are the basic colors, 16–231 are a 6×6×6 color cube, and 232–255 are a
+// grayscale ramp.
+func Indexed(n uint8) Color { return colorIndexed | Color(n) }
+
+// RGB returns the 24-bit Color with the given red, green, and blue components.
+func RGB(r, g, b uint8) Color {
+	return colorRGB | Color(r)<<16 | Color(g)<<8 | Color(b)
+}
+
+// Attr is a bitmask of the attributes of a Style other than color.
+type Attr uint8
+
+// AttrNone indicates no attributes.
+const AttrNone Attr = 0
+
+// Constant bits of enumerated type Attr.
+//
+// The bit order matches the order of the SGR parameters in attrParam.
+const (
+	AttrBold Attr = 1 << iota
+	AttrDim
+	AttrItalic
+	AttrUnderline
+	AttrReverse
+)
+
+// attrParam contains the SGR parameter of each bit of type Attr.
+var attrParam = [...]uint32{1, 2, 3, 4, 7}
+
+// Style defines the colors and attributes of glyphs drawn on the display.
+//
+// The zero value is the terminal's default style. Style is a small value type
+// whose methods return a modified copy, so that styles are built by chaining
+// method calls, e.g., Style{}.Foreground(Red).Bold().
+type Style struct {
+	Fg, Bg Color
+	Attr   Attr
+}
+
+// Foreground returns s with foreground color c.
+func (s Style) Foreground(c Color) Style { s.Fg = c; return s }
+
+// Background returns s with background color c.
+func (s Style) Background(c Color) Style { s.Bg = c; return s }
+
+// With returns s with the attributes a added.
+func (s Style) With(a Attr) Style { s.Attr |= a; return s }
+
+// Without returns s with the attributes a removed.
+func (s Style) Without(a Attr) Style { s.Attr &^= a; return s }
+
+// Bold returns s with increased intensity.
+func (s Style) Bold() Style { return s.With(AttrBold) }
+
+// Dim returns s with decreased intensity.
+func (s Style) Dim() Style { return s.With(AttrDim) }
+
+// Italic returns s italicized.
+func (s Style) Italic() Style { return s.With(AttrItalic) }
+
+// Underline returns s underlined.
+func (s Style) Underline() Style { return s.With(AttrUnderline) }
+
+// Reverse returns s with foreground and background colors swapped.
+func (s Style) Reverse() Style { return s.With(AttrReverse) }
+
+// IsDefault returns true if and only if s is the terminal's default style.
+func (s Style) IsDefault() bool { return s == Style{} }
+
+// SGR writes Select Graphic Rendition (SGR) control sequences, which set the
+// Style of all glyphs drawn after them.
+//
+// Each sequence first resets the style to the terminal's default, so that the
+// resulting style does not depend on any style previously set. The sequence of
+// the zero Style only resets the style.
+//
+// Styles are downgraded to the configured color Depth before they are written;
+// see Style.Downgrade. Nothing is written with DepthNone.
+//
+// SGR retains the storage used to encode numeric parameters, so that writing a
+// sequence does not allocate memory.
+type SGR struct {
+	ascii ascii.Uint32
+	runes runeWriter
+	depth Depth
+	sep   bool
+	err   error
+}
+
+// Depth returns the color depth to which styles are downgraded.
+func (g *SGR) Depth() Depth { return g.depth }
+
+// SetDepth sets the color depth to which styles are downgraded.
+func (g *SGR) SetDepth(d Depth) { g.depth = d }
+
+// Write appends the control sequence that sets style s to w, unless the color
+// depth is DepthNone.
+func (g *SGR) Write(w wire.Writer, s Style) (err error) {
+	return g.write(w, s)
+}
+
+// Runes copies the control sequence that sets style s into p, e.g., the buffer
+// passed to a display.Prompter, and returns the number of runes copied.
+//
+// If p is too short to contain the entire sequence, or the color depth is
+// DepthNone, Runes returns 0, and the contents of p are unspecified.
+func (g *SGR) Runes(p []rune, s Style) (n int) {
+	g.runes = runeWriter{p: p}
+	if g.write(&g.runes, s) != nil {
+		return 0
+	}
+	return g.runes.n
+}
+
+// byteWriter is the subset of wire.Writer used to write control sequences.
+type byteWriter interface {
+	io.Writer
+	io.ByteWriter
+}
+
+// write appends the control sequence that sets style s to w, and returns the
+// first error encountered.
+func (g *SGR) write(w byteWriter, s Style) error {
+	g.sep, g.err = false, nil
+	if g.depth >= DepthNone {
+		return nil
+	}
+	s = s.Downgrade(g.depth)
+	g.bytes(w, CSI)
+	g.param(w, 0)
+	for i, p := range attrParam {
+		if s.Attr&(1<<i) != 0 {
+			g.param(w, p)
+		}
+	}
+	g.color(w, s.Fg, 30)
+	g.color(w, s.Bg, 40)
+	g.byte(w, 'm')
+	return g.err
+}
+
+// color appends the parameters that select color c to w, where base is the
+// parameter of the first basic color: 30 for foreground, or 40 for background.
+// Nothing is appended for the default color.
+func (g *SGR) color(w byteWriter, c Color, base uint32) {
+	n := uint32(c &^ colorKind)
+	switch c & colorKind {
+	case colorBasic:
+		if n < 8 {
+			g.param(w, base+n)
+		} else {
+			g.param(w, base+60+n-8) // aixterm bright colors
+		}
+	case colorIndexed:
+		g.param(w, base+8)
+		g.param(w, 5)
+		g.param(w, n)
+	case colorRGB:
+		g.param(w, base+8)
+		g.param(w, 2)
+		g.param(w, n>>16&0xFF)
+		g.param(w, n>>8&0xFF)
+		g.param(w, n&0xFF)
+	}
+}
+
+// param appends the numeric parameter n to w, preceded by a separator if it is
+// not the first parameter of the sequence.
+func (g *SGR) param(w byteWriter, n uint32) {
+	if g.sep {
+		g.byte(w, ';')
+	}
+	g.sep = true
+	g.ascii.Val = n
+	if _, err := g.ascii.WriteTo(w); g.err == nil {
+		g.err = err
+	}
+}
+
+// bytes appends p to w.
+func (g *SGR) bytes(w byteWriter, p []byte) {
+	if _, err := w.Write(p); g.err == nil {
+		g.err = err
+	}
+}
+
+// byte appends b to w.
+func (g *SGR) byte(w byteWriter, b byte) {
+	if err := w.WriteByte(b); g.err == nil {
+		g.err = err
+	}
+}
+
+// runeWriter copies each byte written to it into a fixed slice of runes.
+type runeWriter struct {
+	p []rune
+	n int
+}
+
+// Write copies the bytes in b into r.
+func (r *runeWriter) Write(b []byte) (n int, err error) {
+	for _, c := range b {
+		if err = r.WriteByte(c); err != nil {
+			return
+		}
+		n++
+	}
+	return
+}
+
+// WriteByte copies c into r.
+func (r *runeWriter) WriteByte(c byte) error {
+	if r.n >= len(r.p) {
+		return &errors.ErrWriteOverflow
+	}
+	r.p[r.n] = rune(c)
+	r.n++
+	return nil
+}
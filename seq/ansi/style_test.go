@@ -0,0 +1,38 @@
+package ansi
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSGR_Runes(t *testing.T) {
+	t.Parallel()
+	for name, tt := range map[string]struct {
+		style Style
+		size  int
+		want  string
+	}{
+		"default":    {style: Style{}, size: 8, want: "\x1b[0m"},
+		"bold":       {style: Style{}.Bold(), size: 8, want: "\x1b[0;1m"},
+		"attrs":      {style: Style{}.Underline().Reverse().Italic().Dim(), size: 16, want: "\x1b[0;2;3;4;7m"},
+		"basic":      {style: Style{}.Foreground(Red).Background(Blue), size: 16, want: "\x1b[0;31;44m"},
+		"bright":     {style: Style{}.Foreground(BrightWhite).Background(BrightBlack), size: 16, want: "\x1b[0;97;100m"},
+		"indexed":    {style: Style{}.Foreground(Indexed(208)), size: 16, want: "\x1b[0;38;5;208m"},
+		"rgb":        {style: Style{}.Background(RGB(1, 128, 255)).Bold(), size: 32, want: "\x1b[0;1;48;2;1;128;255m"},
+		"without":    {style: Style{}.Bold().Dim().Without(AttrBold), size: 8, want: "\x1b[0;2m"},
+		"overflow":   {style: Style{}.Foreground(Indexed(208)), size: 8, want: ""},
+		"exact-size": {style: Style{}.Bold(), size: 6, want: "\x1b[0;1m"},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var g SGR
+			p := make([]rune, tt.size)
+			n := g.Runes(p, tt.style)
+			if diff := cmp.Diff(tt.want, string(p[:n])); len(diff) > 0 {
+				t.Errorf("diff (-want +got):%s\n", diff)
+			}
+		})
+	}
+}
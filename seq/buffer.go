@@ -8,6 +8,7 @@ import (
 	"github.com/ardnew/embedit/config/limits"
 	"github.com/ardnew/embedit/errors"
 	"github.com/ardnew/embedit/seq/ansi"
+	"github.com/ardnew/embedit/seq/ascii"
 	"github.com/ardnew/embedit/seq/eol"
 	"github.com/ardnew/embedit/terminal/key"
 	"github.com/ardnew/embedit/util"
@@ -18,9 +19,12 @@ import (
 type Buffer struct {
 	Byte  [limits.BytesPerBuffer]byte
 	skey  [limits.MaxBytesPerKey]byte
+	sarg  [maxParamsPerKey]int // Parameters of the last control sequence parsed
+	narg  int                  // Number of elements of sarg in use
 	head  volatile.Register32
 	tail  volatile.Register32
 	mode  eol.Mode
+	cr    bool // Last byte parsed was CR
 	valid bool
 }
 
@@ -45,6 +49,22 @@ func (buf *Buffer) init() *Buffer {
 	return buf.reset()
 }
 
+// Mode returns the end-of-line sequence convention of buf.
+func (buf *Buffer) Mode() eol.Mode {
+	if buf == nil || !buf.valid {
+		return eol.Auto
+	}
+	return buf.mode
+}
+
+// SetMode sets the end-of-line sequence convention of buf.
+func (buf *Buffer) SetMode(mode eol.Mode) {
+	if buf == nil || !buf.valid {
+		return
+	}
+	buf.mode = mode
+}
+
 // Len returns the number of bytes in buf.
 func (buf *Buffer) Len() int {
 	if buf == nil || !buf.valid {
@@ -261,7 +281,7 @@ func (buf *Buffer) writeTo(w io.Writer, lo, hi int) (n int, err error) {
 	switch buf.mode {
 	case eol.LF:
 		n, err = w.Write(buf.Byte[lo:hi])
-	case eol.CRLF, eol.CR:
+	default:
 		// We need to translate all LF bytes in our Buffer for the configured EOL.
 		// Repeatedly write up to the next LF in the given range, then write our
 		// configured EOL sequence, and repeat until the range has been covered.
@@ -417,6 +437,35 @@ func (buf *Buffer) WriteByte(b byte) (err error) {
 	return nil
 }
 
+// Strip removes every occurrence of the bytes a and b from buf, and returns the
+// last such byte removed, or 0 if neither was found.
+//
+// The order of all other bytes in buf is preserved.
+func (buf *Buffer) Strip(a, b byte) (last byte) {
+	if buf == nil || !buf.valid {
+		return 0
+	}
+	h, t := buf.head.Get(), buf.tail.Get()
+	w := h
+	for i := h; i != t; i++ {
+		c := buf.Byte[i%limits.BytesPerBuffer]
+		if c == a || c == b {
+			last = c
+			continue
+		}
+		if w != i {
+			buf.Byte[w%limits.BytesPerBuffer] = c
+		}
+		w++
+	}
+	if w == h {
+		_ = buf.reset()
+	} else {
+		buf.tail.Set(w)
+	}
+	return
+}
+
 // WriteEOL appends the configured end of line sequence to buf.
 func (buf *Buffer) WriteEOL() (n int, err error) {
 	i, err := buf.mode.WriteTo(buf)
@@ -431,21 +480,191 @@ func (buf *Buffer) WriteEOL() (n int, err error) {
 // disciplines.
 //
 // Parse consumes the bytes that contribute to the returned key r.
-// If an entire sequence could not be parsed, no bytes are consumed.
+// If an entire sequence could not be parsed, no bytes are consumed other than
+// those discarded by the end-of-line Mode of buf (e.g., the LF of CRLF).
+//
+// Parse is the rune-based equivalent of ParseEvent. Any modifier keys or other
+// information not representable by a single rune are discarded.
 func (buf *Buffer) Parse(isPasting bool) (r rune, n int) {
-	r, n = buf.parse(isPasting)
+	r, _, n = buf.parse(isPasting)
 	if n > 0 {
 		buf.head.Set(buf.head.Get() + uint32(n))
 	}
 	return
 }
 
-func (buf *Buffer) parse(isPasting bool) (r rune, n int) {
+// ParseEvent tries to parse a key sequence from buf.
+// If successful, it returns the decoded key event ev and its size n in bytes.
+// Otherwise, it returns an event with code key.Error and n=0.
+//
+// ParseEvent consumes the bytes that contribute to the returned event, and
+// those same bytes are copied into the event.
+// If an entire sequence could not be parsed, no bytes are consumed other than
+// those discarded by the end-of-line Mode of buf (e.g., the LF of CRLF).
+//
+// Identical sequences of a repeatable key (see key.IsRepeatable) that follow
+// the first in buf are also consumed, and counted in the event's Count. The
+// event's bytes are those of a single sequence, and n includes all of them.
+func (buf *Buffer) ParseEvent(isPasting bool) (ev key.Event, n int) {
+	r, mod, n := buf.parse(isPasting)
+	ev = buf.event(r, mod, n)
+	if n > 0 {
+		h := buf.head.Get() + uint32(n)
+		if !isPasting && key.IsRepeatable(r) {
+			count := uint16(1)
+			for ; count < 1<<16-1 && buf.repeats(h, n); count++ {
+				h += uint32(n)
+			}
+			if count > 1 {
+				ev.Count = count
+			}
+			n = int(h - buf.head.Get())
+		}
+		buf.head.Set(h)
+	}
+	return
+}
+
+// repeats returns true if and only if the n bytes of buf starting at index h
+// equal the leading n bytes of the sequence last parsed into buf.skey.
+func (buf *Buffer) repeats(h uint32, n int) bool {
+	if buf.tail.Get()-h < uint32(n) {
+		return false
+	}
+	for i := 0; i < n; i++ {
+		if buf.Byte[(h+uint32(i))%limits.BytesPerBuffer] != buf.skey[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseRune tries to decode a single UTF-8 encoded rune from buf, without
+// translating control codes, escape sequences, or end-of-line bytes.
+// If successful, it returns the decoded rune r and its size n in bytes.
+// Otherwise, it returns key.Error and n=0.
+//
+// ParseRune consumes the bytes of the returned rune. An invalid encoding is
+// consumed one byte at a time, and returned as utf8.RuneError with n=1.
+//
+// ParseRune is used to insert the next key received literally, e.g., following
+// key.QuotedInsert.
+func (buf *Buffer) ParseRune() (r rune, n int) {
+	if buf == nil || !buf.valid {
+		return key.Error, 0
+	}
 	h, t := buf.head.Get(), buf.tail.Get()
-	size := t - h // Number of bytes currently in buf.
+	size := t - h
 	if size == 0 {
 		return key.Error, 0
 	}
+	if size > utf8.UTFMax {
+		size = utf8.UTFMax
+	}
+	var p [utf8.UTFMax]byte
+	for i := uint32(0); i < size; i++ {
+		p[i] = buf.Byte[(h+i)%limits.BytesPerBuffer]
+	}
+	if !utf8.FullRune(p[:size]) {
+		return key.Error, 0
+	}
+	r, n = utf8.DecodeRune(p[:size])
+	buf.cr = false
+	if h+uint32(n) == t {
+		_ = buf.reset()
+	} else {
+		buf.head.Set(h + uint32(n))
+	}
+	return
+}
+
+// Extract removes the first key sequence in buf with key code k, and returns its
+// decoded key event. Returns ok=false if no such key sequence was found.
+//
+// Only sequences beginning with ESC are considered, so k should be a key code
+// recognized from a control sequence, such as key.CursorPosition. The order of
+// all other bytes in buf is preserved.
+//
+// Extract is used to receive replies to queries sent to the terminal without
+// discarding any keys that were received before the reply.
+func (buf *Buffer) Extract(k rune) (ev key.Event, ok bool) {
+	if buf == nil || !buf.valid {
+		return ev, false
+	}
+	h, t, cr := buf.head.Get(), buf.tail.Get(), buf.cr
+	for i := h; i != t; i++ {
+		if buf.Byte[i%limits.BytesPerBuffer] != ansi.Escape {
+			continue
+		}
+		// Parse the sequence at i as if it were the first in buf.
+		buf.head.Set(i)
+		r, mod, n := buf.parse(false)
+		if r != k || n == 0 {
+			continue
+		}
+		ev, ok = buf.event(r, mod, n), true
+		// Shift all following bytes left in place of the extracted sequence.
+		for j := i; j+uint32(n) != t; j++ {
+			buf.Byte[j%limits.BytesPerBuffer] =
+				buf.Byte[(j+uint32(n))%limits.BytesPerBuffer]
+		}
+		t -= uint32(n)
+		break
+	}
+	buf.cr = cr
+	if h == t {
+		_ = buf.reset()
+	} else {
+		buf.head.Set(h)
+		buf.tail.Set(t)
+	}
+	return
+}
+
+// event returns the key event with key code or rune r and modifiers mod, which
+// was decoded by parse from the leading n bytes of buf.
+func (buf *Buffer) event(r rune, mod key.Modifier, n int) (ev key.Event) {
+	ev.Set(r)
+	ev.Mod = mod
+	switch {
+	case key.IsMouse(r) && buf.narg == 3:
+		ev.X, ev.Y = uint16(buf.sarg[1]), uint16(buf.sarg[2])
+	case r == key.CursorPosition && buf.narg == 2:
+		ev.X, ev.Y = uint16(buf.sarg[1]), uint16(buf.sarg[0])
+	case r == key.WindowSize && buf.narg == 3:
+		ev.X, ev.Y = uint16(buf.sarg[2]), uint16(buf.sarg[1])
+	}
+	if n > 0 {
+		ev.SetBytes(buf.skey[:n])
+	}
+	return
+}
+
+// discardEOL consumes all leading bytes in buf that are discarded by the
+// end-of-line Mode of buf.
+func (buf *Buffer) discardEOL() {
+	h, t := buf.head.Get(), buf.tail.Get()
+	for h != t && buf.mode.Discards(buf.Byte[h%limits.BytesPerBuffer], buf.cr) {
+		buf.cr = false
+		h++
+	}
+	if h == t {
+		_ = buf.reset()
+	} else {
+		buf.head.Set(h)
+	}
+}
+
+func (buf *Buffer) parse(isPasting bool) (r rune, mod key.Modifier, n int) {
+	buf.discardEOL()
+	h, t := buf.head.Get(), buf.tail.Get()
+	size := t - h // Number of bytes currently in buf.
+	if size == 0 {
+		return key.Error, key.ModNone, 0
+	}
+	// Any byte not discarded ends a CRLF or CR NUL sequence.
+	buf.cr = false
+
 	// Size is the minimum among:
 	//   a.) the number bytes in buf.Byte; or
 	//   b.) the maximum length of a key sequence (cap(buf.skey)).
@@ -461,167 +680,64 @@ func (buf *Buffer) parse(isPasting bool) (r rune, n int) {
 	for i := size; i < limits.MaxBytesPerKey; i++ {
 		buf.skey[i] = 0 // Zero out remaining bytes in []skey.
 	}
+	// End-of-line is recognized even when pasting. Any subsequent LF or NUL that
+	// forms a CRLF or CR NUL sequence with CR is discarded by discardEOL.
+	if c := buf.skey[0]; c == ascii.CR || c == ascii.LF {
+		buf.cr = c == ascii.CR
+		return key.Enter, key.ModNone, 1
+	}
 	if !isPasting {
 		// Single byte translations of ASCII control codes to application-defined
 		// runes that represent control sequences.
 		switch buf.skey[0] {
 		case ansi.CtrlA:
-			return key.Home, 1
+			return key.Home, key.ModNone, 1
 		case ansi.CtrlB:
-			return key.Left, 1
+			return key.Left, key.ModNone, 1
 		case ansi.CtrlC:
-			return key.Interrupt, 1
+			return key.Interrupt, key.ModNone, 1
 		case ansi.CtrlD:
-			return key.EndOfFile, 1
+			return key.EndOfFile, key.ModNone, 1
 		case ansi.CtrlE:
-			return key.End, 1
+			return key.End, key.ModNone, 1
 		case ansi.CtrlF:
-			return key.Right, 1
+			return key.Right, key.ModNone, 1
 		case ansi.CtrlH:
-			return key.Backspace, 1
+			return key.Backspace, key.ModNone, 1
 		case ansi.CtrlK:
-			return key.Kill, 1
+			return key.Kill, key.ModNone, 1
 		case ansi.CtrlL:
-			return key.ClearScreen, 1
-		case ansi.CtrlM:
-			return key.Enter, 1
+			return key.ClearScreen, key.ModNone, 1
 		case ansi.CtrlN:
-			return key.Down, 1
+			return key.Down, key.ModNone, 1
 		case ansi.CtrlP:
-			return key.Up, 1
+			return key.Up, key.ModNone, 1
 		case ansi.CtrlU:
-			return key.KillPrevious, 1
+			return key.KillPrevious, key.ModNone, 1
+		case ansi.CtrlV:
+			return key.QuotedInsert, key.ModNone, 1
 		case ansi.CtrlW:
-			return key.DeleteWord, 1
+			return key.DeleteWord, key.ModNone, 1
 		case ansi.Backspace:
-			return key.Backspace, 1
+			return key.Backspace, key.ModNone, 1
 		}
 	}
 	// UTF-8 runes
 	if buf.skey[0] != ansi.Escape {
-		if !utf8.FullRune(buf.skey[0:]) {
-			return key.Error, 0
+		if !utf8.FullRune(buf.skey[:size]) {
+			return key.Error, key.ModNone, 0
 		}
-		return utf8.DecodeRune(buf.skey[0:])
+		r, n = utf8.DecodeRune(buf.skey[:size])
+		return r, key.ModNone, n
 	}
 	// ANSI escape sequences
 	if bytes.HasPrefix(buf.skey[0:], ansi.CSI) {
 		if isPasting {
 			if bytes.HasPrefix(buf.skey[0:], ansi.EOP) {
-				return key.PasteEnd, len(ansi.EOP)
+				return key.PasteEnd, key.ModNone, len(ansi.EOP)
 			}
 		} else {
-			switch size {
-
-			case 3:
-				// xterm sequences
-				switch buf.skey[2] {
-				case 'A':
-					return key.Up, 3
-				case 'B':
-					return key.Down, 3
-				case 'C':
-					return key.Right, 3
-				case 'D':
-					return key.Left, 3
-				case 'H':
-					return key.Home, 3
-				case 'F':
-					return key.End, 3
-				}
-			case 4:
-				// vt sequences
-				if buf.skey[3] == '~' {
-					switch buf.skey[2] {
-					case '1':
-						return key.Home, 4
-					case '2':
-						return key.Insert, 4
-					case '3':
-						return key.Delete, 4
-					case '4':
-						return key.End, 4
-					case '5':
-						return key.PageUp, 4
-					case '6':
-						return key.PageDown, 4
-					case '7':
-						return key.Home, 4
-					case '8':
-						return key.End, 4
-					}
-				}
-			case 5:
-				// vt sequences
-				if buf.skey[4] == '~' {
-					switch buf.skey[2] {
-					case '1':
-						switch buf.skey[3] {
-						case '0':
-							return key.F0, 5
-						case '1':
-							return key.F1, 5
-						case '2':
-							return key.F2, 5
-						case '3':
-							return key.F3, 5
-						case '4':
-							return key.F4, 5
-						case '5':
-							return key.F5, 5
-						case '7':
-							return key.F6, 5
-						case '8':
-							return key.F7, 5
-						case '9':
-							return key.F8, 5
-						}
-					case '2':
-						switch buf.skey[3] {
-						case '0':
-							return key.F9, 5
-						case '1':
-							return key.F10, 5
-						case '3':
-							return key.F11, 5
-						case '4':
-							return key.F12, 5
-						case '5':
-							return key.F13, 5
-						case '6':
-							return key.F14, 5
-						case '8':
-							return key.F15, 5
-						case '9':
-							return key.F16, 5
-						}
-					case '3':
-						switch buf.skey[3] {
-						case '1':
-							return key.F17, 5
-						case '2':
-							return key.F18, 5
-						case '3':
-							return key.F19, 5
-						case '4':
-							return key.F20, 5
-						}
-					}
-				}
-			case 6:
-				// xterm sequences
-				if buf.skey[2] == '1' && buf.skey[3] == ';' && buf.skey[4] == '3' {
-					switch buf.skey[5] {
-					case 'C':
-						return key.AltRight, 6
-					case 'D':
-						return key.AltLeft, 6
-					}
-				}
-			}
-			if bytes.HasPrefix(buf.skey[0:], ansi.SOP) {
-				return key.PasteStart, len(ansi.SOP)
-			}
+			return buf.parseCSI(int(size))
 		}
 	}
 
@@ -631,10 +747,217 @@ func (buf *Buffer) parse(isPasting bool) (r rune, n int) {
 	// them all, but it seems [a-zA-Z~] only appears at the end of a sequence.
 	for i, c := range buf.skey[0:] {
 		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '~' {
-			return key.Unknown, i + 1
+			return key.Unknown, key.ModNone, i + 1
+		}
+	}
+	return key.Error, key.ModNone, 0
+}
+
+// maxParamsPerKey defines the maximum number of numeric parameters decoded from
+// a single control sequence. Additional parameters are ignored.
+const maxParamsPerKey = 4
+
+// parseCSI decodes the control sequence (ESC [ ...) in the first size bytes of
+// buf.skey.
+//
+// A control sequence consists of the CSI prefix, any number of parameter bytes
+// (0x30–0x3F), any number of intermediate bytes (0x20–0x2F), and one final byte
+// (0x40–0x7E). If the final byte has not yet been received, parseCSI returns
+// key.Error and n=0 so that the caller waits for more input.
+//
+// The numeric parameters are retained in buf.sarg until the next call.
+func (buf *Buffer) parseCSI(size int) (r rune, mod key.Modifier, n int) {
+	var prefix byte
+	count := 0
+	for i := range buf.sarg {
+		buf.sarg[i] = 0
+	}
+	for n = len(ansi.CSI); n < size; n++ {
+		c := buf.skey[n]
+		switch {
+		case '0' <= c && c <= '9':
+			if count == 0 {
+				count = 1
+			}
+			if count <= maxParamsPerKey {
+				buf.sarg[count-1] = buf.sarg[count-1]*10 + int(c-'0')
+			}
+		case c == ';':
+			if count == 0 {
+				count = 1
+			}
+			count++
+		case '<' <= c && c <= '?' && n == len(ansi.CSI):
+			// Private parameter prefix, e.g., the '<' in an SGR mouse report.
+			prefix = c
+		case 0x20 <= c && c <= 0x3F:
+			// Intermediate byte or misplaced parameter byte; ignored.
+		case 0x40 <= c && c <= 0x7E:
+			if count > maxParamsPerKey {
+				count = maxParamsPerKey
+			}
+			buf.narg = count
+			r, mod = csiKey(prefix, c, buf.sarg[:count])
+			return r, mod, n + 1
+		default:
+			// Not a valid byte in a control sequence. Discard everything before it.
+			return key.Unknown, key.ModNone, n
+		}
+	}
+	if size < limits.MaxBytesPerKey {
+		// Partial sequence; wait for the final byte.
+		return key.Error, key.ModNone, 0
+	}
+	// Sequence is longer than we are able to parse. Discard it.
+	return key.Unknown, key.ModNone, size
+}
+
+// csiKey returns the key code and modifiers of a control sequence with the
+// given private prefix (or 0), final byte, and numeric parameters.
+//
+// Cursor keys have the form ESC [ 1 ; <mod> <final>, and editing and function
+// keys have the form ESC [ <code> ; <mod> ~, where <mod> is optional.
+func csiKey(prefix, final byte, param []int) (r rune, mod key.Modifier) {
+	if prefix == '<' {
+		return mouseKey(final, param)
+	}
+	if prefix != 0 {
+		return key.Unknown, key.ModNone
+	}
+	if len(param) > 1 {
+		mod = key.ModifierParam(param[1])
+	}
+	switch final {
+	case 'A':
+		r = key.Up
+	case 'B':
+		r = key.Down
+	case 'C':
+		r = key.Right
+	case 'D':
+		r = key.Left
+	case 'H':
+		r = key.Home
+	case 'F':
+		r = key.End
+	case 'R':
+		// Cursor position report (CPR) with the form ESC [ <row> ; <column> R,
+		// which is the reply to a device status report (DSR) query ESC [ 6 n.
+		//
+		// Note that some terminals send the same sequence for F3 with modifier
+		// keys (e.g., ESC [ 1 ; 2 R for Shift+F3), which is always recognized as a
+		// cursor position report.
+		if len(param) != 2 {
+			return key.Unknown, key.ModNone
+		}
+		return key.CursorPosition, key.ModNone
+	case 't':
+		// Window size report with the form ESC [ 8 ; <rows> ; <columns> t, which is
+		// the reply to the query ESC [ 18 t (XTWINOPS), and which is also inserted
+		// into the input by sys.SizeWatcher when the window is resized.
+		if len(param) != 3 || param[0] != 8 {
+			return key.Unknown, key.ModNone
+		}
+		return key.WindowSize, key.ModNone
+	case '~':
+		if len(param) == 0 {
+			return key.Unknown, key.ModNone
+		}
+		switch code := param[0]; code {
+		case 1, 7:
+			r = key.Home
+		case 2:
+			r = key.Insert
+		case 3:
+			r = key.Delete
+		case 4, 8:
+			r = key.End
+		case 5:
+			r = key.PageUp
+		case 6:
+			r = key.PageDown
+		case 200:
+			r = key.PasteStart
+		case 201:
+			r = key.PasteEnd
+		default:
+			r = key.Unknown
+			if code -= functionKeyBase; 0 <= code && code < len(functionKey) {
+				if fn := functionKey[code]; fn != 0 {
+					r = fn
+				}
+			}
+		}
+	default:
+		return key.Unknown, key.ModNone
+	}
+	// Alt+Left and Alt+Right have their own key codes for compatibility with the
+	// rune-based API.
+	if mod == key.ModAlt {
+		switch r {
+		case key.Left:
+			r = key.AltLeft
+		case key.Right:
+			r = key.AltRight
 		}
 	}
-	return key.Error, 0
+	return
+}
+
+// mouseKey returns the key code and modifiers of an SGR mouse report with the
+// form ESC [ < <button> ; <column> ; <row> <final>, where final is 'M' for a
+// button press and 'm' for a button release.
+func mouseKey(final byte, param []int) (r rune, mod key.Modifier) {
+	if len(param) != 3 || (final != 'M' && final != 'm') {
+		return key.Unknown, key.ModNone
+	}
+	button := param[0]
+	if button&4 != 0 {
+		mod |= key.ModShift
+	}
+	if button&8 != 0 {
+		mod |= key.ModAlt
+	}
+	if button&16 != 0 {
+		mod |= key.ModCtrl
+	}
+	switch {
+	case button&32 != 0:
+		// Motion events are not requested by ansi.MSE.
+		return key.Unknown, key.ModNone
+	case button&64 != 0:
+		if button&1 == 0 {
+			return key.MouseWheelUp, mod
+		}
+		return key.MouseWheelDown, mod
+	case final == 'm':
+		return key.MouseRelease, mod
+	}
+	switch button & 3 {
+	case 0:
+		r = key.MouseLeft
+	case 1:
+		r = key.MouseMiddle
+	case 2:
+		r = key.MouseRight
+	default:
+		r = key.MouseRelease
+	}
+	return
+}
+
+// functionKeyBase is the parameter of the first function key sequence (F0) in
+// functionKey.
+const functionKeyBase = 10
+
+// functionKey maps the parameter of function key sequences ESC [ <code> ~,
+// offset by functionKeyBase, to a key code. Unassigned codes are 0.
+var functionKey = [...]rune{
+	key.F0, key.F1, key.F2, key.F3, key.F4, key.F5, 0, // 10..16
+	key.F6, key.F7, key.F8, key.F9, key.F10, 0, // 17..22
+	key.F11, key.F12, key.F13, key.F14, 0, // 23..27
+	key.F15, key.F16, 0, // 28..30
+	key.F17, key.F18, key.F19, key.F20, // 31..34
 }
 
 func (buf *Buffer) Last() []byte {
@@ -10,6 +10,7 @@ import (
 
 	"github.com/ardnew/embedit/config/limits"
 	"github.com/ardnew/embedit/seq/eol"
+	"github.com/ardnew/embedit/terminal/key"
 	"github.com/ardnew/embedit/volatile"
 )
 
@@ -201,3 +202,197 @@ func TestBuffer_WriteTo(t *testing.T) {
 		})
 	}
 }
+
+func TestBuffer_ParseEvent(t *testing.T) {
+	t.Parallel()
+	for name, tt := range map[string]struct {
+		in      string
+		isPaste bool
+		wantKey rune
+		wantMod key.Modifier
+		wantX   uint16
+		wantY   uint16
+		wantN   int
+		wantCnt uint16
+	}{
+		"empty":         {in: "", wantKey: key.Error, wantN: 0},
+		"rune":          {in: "a", wantKey: 'a', wantN: 1},
+		"rune-utf8":     {in: "é", wantKey: 'é', wantN: 2},
+		"rune-partial":  {in: "\xc3", wantKey: key.Error, wantN: 0},
+		"ctrl-a":        {in: "\x01", wantKey: key.Home, wantN: 1},
+		"up":            {in: "\x1b[A", wantKey: key.Up, wantN: 3},
+		"up-then-rune":  {in: "\x1b[Ax", wantKey: key.Up, wantN: 3},
+		"ctrl-right":    {in: "\x1b[1;5C", wantKey: key.Right, wantMod: key.ModCtrl, wantN: 6},
+		"alt-left":      {in: "\x1b[1;3D", wantKey: key.AltLeft, wantMod: key.ModAlt, wantN: 6},
+		"delete":        {in: "\x1b[3~", wantKey: key.Delete, wantN: 4},
+		"shift-delete":  {in: "\x1b[3;2~", wantKey: key.Delete, wantMod: key.ModShift, wantN: 6},
+		"f12":           {in: "\x1b[24~", wantKey: key.F12, wantN: 5},
+		"partial-csi":   {in: "\x1b[1;", wantKey: key.Error, wantN: 0},
+		"unknown-csi":   {in: "\x1b[99~", wantKey: key.Unknown, wantN: 5},
+		"paste-start":   {in: "\x1b[200~", wantKey: key.PasteStart, wantN: 6},
+		"paste-end":     {in: "\x1b[201~", isPaste: true, wantKey: key.PasteEnd, wantN: 6},
+		"paste-literal": {in: "\x01", isPaste: true, wantKey: '\x01', wantN: 1},
+		"mouse-left":    {in: "\x1b[<0;12;34M", wantKey: key.MouseLeft, wantX: 12, wantY: 34, wantN: 11},
+		"mouse-release": {in: "\x1b[<0;1;2m", wantKey: key.MouseRelease, wantX: 1, wantY: 2, wantN: 9},
+		"mouse-ctrl":    {in: "\x1b[<18;5;6M", wantKey: key.MouseRight, wantMod: key.ModCtrl, wantX: 5, wantY: 6, wantN: 10},
+		"mouse-wheel":   {in: "\x1b[<65;999;999M", wantKey: key.MouseWheelDown, wantX: 999, wantY: 999, wantN: 14},
+		"cursor-report": {in: "\x1b[24;80R", wantKey: key.CursorPosition, wantX: 80, wantY: 24, wantN: 8},
+		"window-size":   {in: "\x1b[8;24;80t", wantKey: key.WindowSize, wantX: 80, wantY: 24, wantN: 10},
+		"window-other":  {in: "\x1b[4;24;80t", wantKey: key.Unknown, wantN: 10},
+		"repeat-left":   {in: "\x1b[D\x1b[D\x1b[D", wantKey: key.Left, wantN: 9, wantCnt: 3},
+		"repeat-bs":     {in: "\x7f\x7f", wantKey: key.Backspace, wantN: 2, wantCnt: 2},
+		"repeat-then":   {in: "\x1b[D\x1b[Dx", wantKey: key.Left, wantN: 6, wantCnt: 2},
+		"repeat-mod":    {in: "\x1b[D\x1b[1;5D", wantKey: key.Left, wantN: 3},
+		"repeat-part":   {in: "\x1b[D\x1b[", wantKey: key.Left, wantN: 3},
+		"repeat-rune":   {in: "aa", wantKey: 'a', wantN: 1},
+		"repeat-enter":  {in: "\r\r", wantKey: key.Enter, wantN: 1},
+		"repeat-paste":  {in: "\x7f\x7f", isPaste: true, wantKey: '\x7f', wantN: 1},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var b Buffer
+			b.Configure(eol.LF)
+			_, _ = b.Write([]byte(tt.in))
+			ev, n := b.ParseEvent(tt.isPaste)
+			if diff := cmp.Diff(tt.wantN, n); len(diff) > 0 {
+				t.Errorf("diff N (-want +got):%s\n", diff)
+			}
+			if diff := cmp.Diff(tt.wantKey, ev.Key()); len(diff) > 0 {
+				t.Errorf("diff Key (-want +got):%s\n", diff)
+			}
+			if diff := cmp.Diff(tt.wantMod, ev.Mod); len(diff) > 0 {
+				t.Errorf("diff Mod (-want +got):%s\n", diff)
+			}
+			if diff := cmp.Diff([]uint16{tt.wantX, tt.wantY}, []uint16{ev.X, ev.Y}); len(diff) > 0 {
+				t.Errorf("diff X, Y (-want +got):%s\n", diff)
+			}
+			if diff := cmp.Diff(tt.wantCnt, ev.Count); len(diff) > 0 {
+				t.Errorf("diff Count (-want +got):%s\n", diff)
+			}
+			if diff := cmp.Diff(tt.in[:n/ev.Repeat()], string(ev.Bytes())); len(diff) > 0 {
+				t.Errorf("diff Bytes (-want +got):%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestBuffer_Extract(t *testing.T) {
+	t.Parallel()
+	for name, tt := range map[string]struct {
+		in     string
+		wantOK bool
+		wantX  uint16
+		wantY  uint16
+		wantIn string
+	}{
+		"empty":        {in: "", wantIn: ""},
+		"none":         {in: "ab\x1b[A", wantIn: "ab\x1b[A"},
+		"only":         {in: "\x1b[24;80R", wantOK: true, wantX: 80, wantY: 24, wantIn: ""},
+		"surrounded":   {in: "ab\x1b[3;7Rcd", wantOK: true, wantX: 7, wantY: 3, wantIn: "abcd"},
+		"after-key":    {in: "\x1b[D\x1b[1;1R\x1b[C", wantOK: true, wantX: 1, wantY: 1, wantIn: "\x1b[D\x1b[C"},
+		"partial":      {in: "a\x1b[24;8", wantIn: "a\x1b[24;8"},
+		"first-of-two": {in: "\x1b[1;2R\x1b[3;4R", wantOK: true, wantX: 2, wantY: 1, wantIn: "\x1b[3;4R"},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var b Buffer
+			b.Configure(eol.LF)
+			_, _ = b.Write([]byte(tt.in))
+			ev, ok := b.Extract(key.CursorPosition)
+			if diff := cmp.Diff(tt.wantOK, ok); len(diff) > 0 {
+				t.Errorf("diff OK (-want +got):%s\n", diff)
+			}
+			if diff := cmp.Diff([]uint16{tt.wantX, tt.wantY}, []uint16{ev.X, ev.Y}); len(diff) > 0 {
+				t.Errorf("diff X, Y (-want +got):%s\n", diff)
+			}
+			var sb bytes.Buffer
+			_, _ = b.WriteTo(&sb)
+			if diff := cmp.Diff(tt.wantIn, sb.String()); len(diff) > 0 {
+				t.Errorf("diff remaining (-want +got):%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestBuffer_ParseEOL(t *testing.T) {
+	t.Parallel()
+	for name, tt := range map[string]struct {
+		mode eol.Mode
+		in   []string // Each element is written to Buffer before parsing
+		want []rune
+	}{
+		"auto-cr":         {mode: eol.Auto, in: []string{"a\rb"}, want: []rune{'a', key.Enter, 'b'}},
+		"auto-lf":         {mode: eol.Auto, in: []string{"a\nb"}, want: []rune{'a', key.Enter, 'b'}},
+		"auto-crlf":       {mode: eol.Auto, in: []string{"a\r\nb"}, want: []rune{'a', key.Enter, 'b'}},
+		"auto-crnul":      {mode: eol.Auto, in: []string{"a\r\x00b"}, want: []rune{'a', key.Enter, 'b'}},
+		"auto-crlf-split": {mode: eol.Auto, in: []string{"a\r", "\nb"}, want: []rune{'a', key.Enter, 'b'}},
+		"auto-lfcr":       {mode: eol.Auto, in: []string{"\n\r"}, want: []rune{key.Enter, key.Enter}},
+		"auto-crcr":       {mode: eol.Auto, in: []string{"\r\r\n"}, want: []rune{key.Enter, key.Enter}},
+		"lf-crlf":         {mode: eol.LF, in: []string{"a\r\nb"}, want: []rune{'a', key.Enter, key.Enter, 'b'}},
+		"lf-cr":           {mode: eol.LF, in: []string{"a\rb"}, want: []rune{'a', key.Enter, 'b'}},
+		"cr-lf":           {mode: eol.CR, in: []string{"a\nb\r"}, want: []rune{'a', key.Enter, 'b', key.Enter}},
+		"crlf-crlf":       {mode: eol.CRLF, in: []string{"\r\n\r\n"}, want: []rune{key.Enter, key.Enter}},
+		"crlf-lf":         {mode: eol.CRLF, in: []string{"a\nb"}, want: []rune{'a', key.Enter, 'b'}},
+		"crnul-crnul":     {mode: eol.CRNUL, in: []string{"\r\x00\r\x00"}, want: []rune{key.Enter, key.Enter}},
+		"crnul-crlf":      {mode: eol.CRNUL, in: []string{"\r\n"}, want: []rune{key.Enter, key.Enter}},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var b Buffer
+			b.Configure(tt.mode)
+			var got []rune
+			for _, s := range tt.in {
+				_, _ = b.Write([]byte(s))
+				for {
+					r, n := b.Parse(false)
+					if n == 0 {
+						break
+					}
+					got = append(got, r)
+				}
+			}
+			if diff := cmp.Diff(tt.want, got); len(diff) > 0 {
+				t.Errorf("diff (-want +got):%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestBuffer_ParseRune(t *testing.T) {
+	t.Parallel()
+	for name, tt := range map[string]struct {
+		in     string
+		want   rune
+		wantN  int
+		wantIn string
+	}{
+		"empty":      {in: "", want: key.Error},
+		"escape":     {in: "\x1b[A", want: '\x1b', wantN: 1, wantIn: "[A"},
+		"ctrl-v":     {in: "\x16a", want: '\x16', wantN: 1, wantIn: "a"},
+		"cr":         {in: "\r\n", want: '\r', wantN: 1, wantIn: "\n"},
+		"multibyte":  {in: "é!", want: 'é', wantN: 2, wantIn: "!"},
+		"partial":    {in: "\xc3", want: key.Error, wantIn: "\xc3"},
+		"invalid":    {in: "\xffa", want: '�', wantN: 1, wantIn: "a"},
+		"last-of-in": {in: "\t", want: '\t', wantN: 1},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var b Buffer
+			b.Configure(eol.LF)
+			_, _ = b.Write([]byte(tt.in))
+			r, n := b.ParseRune()
+			if diff := cmp.Diff([]int{int(tt.want), tt.wantN}, []int{int(r), n}); len(diff) > 0 {
+				t.Errorf("diff rune, size (-want +got):%s\n", diff)
+			}
+			var sb bytes.Buffer
+			_, _ = b.WriteTo(&sb)
+			if diff := cmp.Diff(tt.wantIn, sb.String()); len(diff) > 0 {
+				t.Errorf("diff remaining (-want +got):%s\n", diff)
+			}
+		})
+	}
+}
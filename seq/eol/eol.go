@@ -7,23 +7,38 @@ import (
 )
 
 // Mode defines end-of-line sequence conventions.
+//
+// When reading input, CR and LF each end a line regardless of Mode, since raw
+// terminals send CR for Enter. The Mode only determines which byte immediately
+// following CR is discarded, so that a device's end-of-line sequence is read as
+// a single end-of-line: Auto discards LF or NUL, CRLF discards LF, and CRNUL
+// discards NUL. Modes LF and CR discard nothing.
+//
+// When writing output, each LF is translated to the sequence of the Mode.
+// Auto is equivalent to CRLF, which is correct for any terminal in raw mode.
+//
+// The zero value of Mode is Auto.
 type Mode byte
 
 // Constants of enumerated type Mode.
 const (
-	LF Mode = iota
+	Auto Mode = iota
+	LF
 	CRLF
 	CR
+	CRNUL
 )
 
 // Platform aliases of Mode constants.
-const Unix, DOS, Mac = LF, CRLF, CR
+const Unix, DOS, Mac, Telnet = LF, CRLF, CR, CRNUL
 
 // ASCII byte sequences of enumerated type Mode.
 var seq = [...][]byte{
-	{ascii.LF},           // LF
-	{ascii.CR, ascii.LF}, // CRLF
-	{ascii.CR},           // CR
+	{ascii.CR, ascii.LF},  // Auto
+	{ascii.LF},            // LF
+	{ascii.CR, ascii.LF},  // CRLF
+	{ascii.CR},            // CR
+	{ascii.CR, ascii.NUL}, // CRNUL
 }
 
 // WriteTo implements io.WriterTo.
@@ -31,3 +46,18 @@ func (m Mode) WriteTo(w io.Writer) (n int64, err error) {
 	i, err := w.Write(seq[m])
 	return int64(i), err
 }
+
+// Discards returns true if and only if m discards byte b when it is received
+// as input, given whether or not the previous byte received was CR.
+func (m Mode) Discards(b byte, afterCR bool) bool {
+	if !afterCR {
+		return false
+	}
+	switch b {
+	case ascii.LF:
+		return m == Auto || m == CRLF
+	case ascii.NUL:
+		return m == Auto || m == CRNUL
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package utf8
+
+// escapeState is the state of an Escape scanner.
+type escapeState uint8
+
+// Constant values of enumerated type escapeState.
+const (
+	escapeGround       escapeState = iota // Not in any escape sequence
+	escapeEscape                          // After ESC
+	escapeIntermediate                    // After ESC and intermediate bytes
+	escapeControl                         // In a control sequence (CSI)
+	escapeShift                           // After a single shift (SS2, SS3)
+	escapeString                          // In a control string (OSC, DCS, ...)
+	escapeStringEscape                    // After ESC in a control string
+)
+
+// Escape scans a sequence of runes to identify those that are part of an
+// escape sequence, as defined by ECMA-48 (ISO/IEC 6429).
+//
+// The following sequences are recognized in both their 7-bit (ESC Fe) and 8-bit
+// (C1) forms:
+//
+//	Control sequence:  CSI P..P I..I F       e.g., ESC[38;5;208m
+//	Control string:    OSC/DCS/SOS/PM/APC ... ST (or BEL)
+//	                                         e.g., ESC]0;title BEL
+//	Single shift:      SS2/SS3 C             e.g., ESCOP
+//
+// Any other ESC is followed by zero or more intermediate bytes and one final
+// byte. A control string is terminated by ST (ESC \ or C1 0x9C), or by BEL as
+// commonly used with OSC.
+//
+// CAN and SUB abort any sequence in progress, including a control string, and
+// are not part of the sequence.
+//
+// The zero value of Escape is ready to scan a new sequence of runes.
+type Escape struct {
+	state escapeState
+}
+
+// Reset prepares e to scan a new sequence of runes.
+func (e *Escape) Reset() {
+	if e != nil {
+		e.state = escapeGround
+	}
+}
+
+// Active returns true if and only if the runes most recently given to Scan form
+// the beginning of an incomplete escape sequence.
+func (e *Escape) Active() bool {
+	return e != nil && e.state != escapeGround
+}
+
+// Scan returns true if and only if r is part of an escape sequence, given the
+// runes previously scanned by e.
+func (e *Escape) Scan(r rune) bool {
+	if e == nil {
+		return false
+	}
+	switch e.state {
+	case escapeGround:
+		return e.introduce(r)
+
+	case escapeEscape:
+		switch {
+		case r == '[':
+			e.state = escapeControl
+		case r == ']', r == 'P', r == 'X', r == '^', r == '_':
+			e.state = escapeString // OSC, DCS, SOS, PM, APC
+		case r == 'N', r == 'O':
+			e.state = escapeShift // SS2, SS3
+		case 0x20 <= r && r <= 0x2F:
+			e.state = escapeIntermediate
+		case 0x30 <= r && r <= 0x7E:
+			e.state = escapeGround
+		default:
+			return e.cancel(r)
+		}
+
+	case escapeIntermediate:
+		switch {
+		case 0x20 <= r && r <= 0x2F:
+		case 0x30 <= r && r <= 0x7E:
+			e.state = escapeGround
+		default:
+			return e.cancel(r)
+		}
+
+	case escapeControl:
+		switch {
+		case 0x20 <= r && r <= 0x3F: // Parameter and intermediate bytes
+		case 0x40 <= r && r <= 0x7E: // Final byte
+			e.state = escapeGround
+		default:
+			return e.cancel(r)
+		}
+
+	case escapeShift:
+		e.state = escapeGround
+
+	case escapeString:
+		switch r {
+		case 0x07, 0x9C: // BEL, ST
+			e.state = escapeGround
+		case 0x1B: // ESC
+			e.state = escapeStringEscape
+		case 0x18, 0x1A: // CAN, SUB
+			e.state = escapeGround
+			return false
+		}
+
+	case escapeStringEscape:
+		if r != '\\' { // ST
+			// ESC followed by anything other than ST aborts the control string and
+			// begins a new escape sequence.
+			e.state = escapeEscape
+			return e.Scan(r)
+		}
+		e.state = escapeGround
+	}
+	return true
+}
+
+// introduce returns true if and only if r introduces an escape sequence, and
+// updates the state of e accordingly.
+func (e *Escape) introduce(r rune) bool {
+	switch r {
+	case 0x1B: // ESC
+		e.state = escapeEscape
+	case 0x9B: // CSI
+		e.state = escapeControl
+	case 0x90, 0x98, 0x9D, 0x9E, 0x9F: // DCS, SOS, OSC, PM, APC
+		e.state = escapeString
+	case 0x8E, 0x8F: // SS2, SS3
+		e.state = escapeShift
+	default:
+		return false
+	}
+	return true
+}
+
+// cancel aborts the escape sequence in progress and returns true if and only if
+// r introduces a new escape sequence.
+//
+// A C0 control character other than ESC within an escape sequence is not part
+// of that sequence.
+func (e *Escape) cancel(r rune) bool {
+	e.state = escapeGround
+	return e.introduce(r)
+}
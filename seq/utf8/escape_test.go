@@ -0,0 +1,98 @@
+package utf8
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEscape_Scan(t *testing.T) {
+	t.Parallel()
+	for name, tt := range map[string]struct {
+		in         string
+		want       string // Runes of in that are not part of an escape sequence
+		wantActive bool
+	}{
+		"plain":             {in: "abc", want: "abc"},
+		"csi-sgr":           {in: "a\x1b[38;5;208mb", want: "ab"},
+		"csi-private":       {in: "\x1b[?2004hx", want: "x"},
+		"csi-intermediate":  {in: "\x1b[2 qx", want: "x"},
+		"csi-c1":            {in: "a\u009b1mb", want: "ab"},
+		"csi-truncated":     {in: "a\x1b[1;3", want: "a", wantActive: true},
+		"csi-control":       {in: "\x1b[1\nx", want: "\nx"},
+		"csi-escape":        {in: "\x1b[1\x1b[2mx", want: "x"},
+		"osc-bel":           {in: "\x1b]0;title\x07> ", want: "> "},
+		"osc-st":            {in: "\x1b]8;;http://x\x1b\\link", want: "link"},
+		"osc-c1-st":         {in: "\u009d0;title\u009c> ", want: "> "},
+		"osc-truncated":     {in: "> \x1b]0;tit", want: "> ", wantActive: true},
+		"osc-escape":        {in: "\x1b]0;t\x1b[1mx", want: "x"},
+		"dcs":               {in: "\x1bPq#0;2;0;0;0\x1b\\x", want: "x"},
+		"dcs-c1":            {in: "\u0090data\u009cx", want: "x"},
+		"apc":               {in: "\x1b_hidden\x1b\\x", want: "x"},
+		"string-can":        {in: "\x1b]0;title\x18x", want: "\x18x"},
+		"string-sub":        {in: "\x1bPdata\x1ax", want: "\x1ax"},
+		"string-escape-can": {in: "\x1b]0;t\x1b\x18x", want: "\x18x"},
+		"csi-can":           {in: "\x1b[1\x18x", want: "\x18x"},
+		"single-shift":      {in: "\x1bOPx", want: "x"},
+		"esc-final":         {in: "\x1b7x\x1b8", want: "x"},
+		"esc-intermediate":  {in: "\x1b(Bx", want: "x"},
+		"esc-truncated":     {in: "x\x1b", want: "x", wantActive: true},
+		"esc-invalid":       {in: "\x1béx", want: "éx"},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var e Escape
+			var got []rune
+			for _, r := range tt.in {
+				if !e.Scan(r) {
+					got = append(got, r)
+				}
+			}
+			if diff := cmp.Diff(tt.want, string(got)); len(diff) > 0 {
+				t.Errorf("diff (-want +got):%s\n", diff)
+			}
+			if diff := cmp.Diff(tt.wantActive, e.Active()); len(diff) > 0 {
+				t.Errorf("diff Active (-want +got):%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestIterable_Width(t *testing.T) {
+	t.Parallel()
+	for name, tt := range map[string]struct {
+		in        string
+		want      int
+		wantTag   int // Width with build tag "nowcwidth"
+		wantCount int
+	}{
+		"plain":      {in: "> ", want: 2, wantTag: 2, wantCount: 2},
+		"sgr":        {in: "\x1b[1;32m> \x1b[0m", want: 2, wantTag: 2, wantCount: 2},
+		"sgr-rgb":    {in: "\x1b[38;2;255;128;0muser\x1b[m$ ", want: 6, wantTag: 6, wantCount: 6},
+		"osc-title":  {in: "\x1b]0;host: ~\x07$ ", want: 2, wantTag: 2, wantCount: 2},
+		"osc-link":   {in: "\x1b]8;;file:///\x1b\\dir\x1b]8;;\x1b\\> ", want: 5, wantTag: 5, wantCount: 5},
+		"c1-csi":     {in: "\u009b7m>\u009b0m ", want: 2, wantTag: 2, wantCount: 2},
+		"wide":       {in: "\x1b[1m漢\x1b[0m> ", want: 4, wantTag: 3, wantCount: 3},
+		"combining":  {in: "é> ", want: 3, wantTag: 4, wantCount: 4},
+		"truncated":  {in: "> \x1b[1", want: 2, wantTag: 2, wantCount: 2},
+		"string-can": {in: "\x1b]0;t\x18> ", want: 2, wantTag: 2, wantCount: 3},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			want := tt.want
+			if nowcwidth {
+				want = tt.wantTag
+			}
+			p := []rune(tt.in)
+			s := Iterable{Iterator: (*IterableRune)(&p)}
+			if diff := cmp.Diff(want, s.Reset().Width()); len(diff) > 0 {
+				t.Errorf("diff Width (-want +got):%s\n", diff)
+			}
+			if diff := cmp.Diff(tt.wantCount, s.Reset().GlyphCount()); len(diff) > 0 {
+				t.Errorf("diff GlyphCount (-want +got):%s\n", diff)
+			}
+		})
+	}
+}
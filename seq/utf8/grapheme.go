@@ -0,0 +1,106 @@
+package utf8
+
+//go:generate go run mktables.go -unicode 14.0.0 -table grapheme
+
+// graphemeProp is the Grapheme_Cluster_Break property of a rune, as defined by
+// Unicode Standard Annex #29.
+type graphemeProp uint8
+
+// Constant values of enumerated type graphemeProp.
+const (
+	graphemeNone graphemeProp = iota // Start of text, no rune has this property
+	graphemeOther
+	graphemeCR
+	graphemeLF
+	graphemeControl
+	graphemeExtend
+	graphemeZWJ
+	graphemeRegionalIndicator
+	graphemePrepend
+	graphemeSpacingMark
+	graphemeL
+	graphemeV
+	graphemeT
+	graphemeLV
+	graphemeLVT
+	graphemeExtendedPictographic
+)
+
+// Graphemes segments a sequence of runes into extended grapheme clusters — the
+// user-perceived characters defined by Unicode Standard Annex #29.
+//
+// The zero value of Graphemes is ready to segment a new sequence of runes.
+//
+// With build tag "nographeme", the table of Grapheme_Cluster_Break properties is
+// not compiled, and every rune other than LF following CR is considered the
+// start of a new grapheme cluster.
+type Graphemes struct {
+	prev graphemeProp // property of the preceding rune
+	pict bool         // preceding runes match ExtPict Extend* (ZWJ)?
+	ri   bool         // odd number of consecutive preceding Regional_Indicator?
+}
+
+// Reset prepares g to segment a new sequence of runes.
+func (g *Graphemes) Reset() {
+	if g != nil {
+		*g = Graphemes{}
+	}
+}
+
+// Break returns true if and only if a grapheme cluster boundary exists between
+// the rune given in the previous call to Break and r. The first rune given
+// after g is reset always begins a new grapheme cluster.
+func (g *Graphemes) Break(r rune) bool {
+	if g == nil {
+		return true
+	}
+	p := graphemeProperty(r)
+	brk := g.breaks(p)
+	switch p {
+	case graphemeExtendedPictographic:
+		g.pict = true
+	case graphemeExtend, graphemeZWJ:
+		g.pict = g.pict && g.prev != graphemeZWJ
+	default:
+		g.pict = false
+	}
+	g.ri = p == graphemeRegionalIndicator &&
+		!(g.prev == graphemeRegionalIndicator && g.ri)
+	g.prev = p
+	return brk
+}
+
+// breaks returns true if and only if the rules of UAX #29 permit a grapheme
+// cluster boundary between the preceding rune and a rune with property p.
+func (g *Graphemes) breaks(p graphemeProp) bool {
+	switch g.prev {
+	case graphemeNone: // GB1
+		return true
+	case graphemeCR: // GB3, GB4
+		return p != graphemeLF
+	case graphemeLF, graphemeControl: // GB4
+		return true
+	}
+	switch p {
+	case graphemeCR, graphemeLF, graphemeControl: // GB5
+		return true
+	case graphemeExtend, graphemeZWJ, graphemeSpacingMark: // GB9, GB9a
+		return false
+	}
+	switch g.prev {
+	case graphemeL: // GB6
+		return p != graphemeL && p != graphemeV &&
+			p != graphemeLV && p != graphemeLVT
+	case graphemeLV, graphemeV: // GB7
+		return p != graphemeV && p != graphemeT
+	case graphemeLVT, graphemeT: // GB8
+		return p != graphemeT
+	case graphemePrepend: // GB9b
+		return false
+	case graphemeZWJ: // GB11
+		return p != graphemeExtendedPictographic || !g.pict
+	case graphemeRegionalIndicator: // GB12, GB13
+		return p != graphemeRegionalIndicator || !g.ri
+	}
+	return true // GB999
+}
@@ -0,0 +1,17 @@
+//go:build nographeme
+// +build nographeme
+
+package utf8
+
+// graphemeProperty returns the Grapheme_Cluster_Break property of r.
+func graphemeProperty(r rune) graphemeProp {
+	switch {
+	case r == '\r':
+		return graphemeCR
+	case r == '\n':
+		return graphemeLF
+	case r < 0x20, 0x7F <= r && r < 0xA0:
+		return graphemeControl
+	}
+	return graphemeOther
+}
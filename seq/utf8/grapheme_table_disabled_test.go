@@ -0,0 +1,8 @@
+//go:build nographeme
+// +build nographeme
+
+package utf8
+
+// nographeme is true if and only if the Grapheme_Cluster_Break table is not
+// compiled.
+const nographeme = true
@@ -0,0 +1,80 @@
+package utf8
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// clusters returns the grapheme clusters of s segmented with g.
+func clusters(g *Graphemes, s string) (c []string) {
+	for _, r := range s {
+		if g.Break(r) || len(c) == 0 {
+			c = append(c, "")
+		}
+		c[len(c)-1] += string(r)
+	}
+	return
+}
+
+// runeClusters returns the grapheme clusters of s when no Grapheme_Cluster_Break
+// properties are known: each rune, except an LF following CR.
+func runeClusters(s string) (c []string) {
+	prev := rune(-1)
+	for _, r := range s {
+		if prev == '\r' && r == '\n' {
+			c[len(c)-1] += string(r)
+		} else {
+			c = append(c, string(r))
+		}
+		prev = r
+	}
+	return
+}
+
+func TestGraphemes_Break(t *testing.T) {
+	t.Parallel()
+	for name, tt := range map[string]struct {
+		in   string
+		want []string
+	}{
+		"ascii":          {in: "abc", want: []string{"a", "b", "c"}},
+		"crlf":           {in: "a\r\nb", want: []string{"a", "\r\n", "b"}},
+		"lfcr":           {in: "\n\r", want: []string{"\n", "\r"}},
+		"control":        {in: "a\t\u0301", want: []string{"a", "\t", "\u0301"}},
+		"combining":      {in: "e\u0301\u0302x", want: []string{"e\u0301\u0302", "x"}},
+		"spacing-mark":   {in: "\u0915\u0903a", want: []string{"\u0915\u0903", "a"}},
+		"prepend":        {in: "\u0600a\u0600", want: []string{"\u0600a", "\u0600"}},
+		"hangul-l-v-t":   {in: "\u1100\u1161\u11A8", want: []string{"\u1100\u1161\u11A8"}},
+		"hangul-lv-t":    {in: "\uAC00\u11A8\uAC00", want: []string{"\uAC00\u11A8", "\uAC00"}},
+		"hangul-l-lvt":   {in: "\u1100\uAC01\u1161", want: []string{"\u1100\uAC01", "\u1161"}},
+		"hangul-t-l":     {in: "\u11A8\u1100", want: []string{"\u11A8", "\u1100"}},
+		"ri-pair":        {in: "\U0001F1FA\U0001F1F8", want: []string{"\U0001F1FA\U0001F1F8"}},
+		"ri-two-pairs":   {in: "\U0001F1FA\U0001F1F8\U0001F1EB\U0001F1F7", want: []string{"\U0001F1FA\U0001F1F8", "\U0001F1EB\U0001F1F7"}},
+		"ri-odd":         {in: "\U0001F1FA\U0001F1F8\U0001F1EBa", want: []string{"\U0001F1FA\U0001F1F8", "\U0001F1EB", "a"}},
+		"ri-after-other": {in: "a\U0001F1FA\U0001F1F8\U0001F1EB", want: []string{"a", "\U0001F1FA\U0001F1F8", "\U0001F1EB"}},
+		"zwj-family":     {in: "\U0001F468\u200D\U0001F469\u200D\U0001F467x", want: []string{"\U0001F468\u200D\U0001F469\u200D\U0001F467", "x"}},
+		"zwj-extend":     {in: "\U0001F468\U0001F3FD\u200D\U0001F469", want: []string{"\U0001F468\U0001F3FD\u200D\U0001F469"}},
+		"zwj-not-pict":   {in: "a\u200D\U0001F469", want: []string{"a\u200D", "\U0001F469"}},
+		"zwj-trailing":   {in: "\U0001F469\u200D", want: []string{"\U0001F469\u200D"}},
+		"emoji-modifier": {in: "\U0001F44D\U0001F3FD\U0001F44D", want: []string{"\U0001F44D\U0001F3FD", "\U0001F44D"}},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			want := tt.want
+			if nographeme {
+				want = runeClusters(tt.in)
+			}
+			var g Graphemes
+			if diff := cmp.Diff(want, clusters(&g, tt.in)); len(diff) > 0 {
+				t.Errorf("diff (-want +got):%s\n", diff)
+			}
+			// The same runes are segmented the same way after Reset.
+			g.Reset()
+			if diff := cmp.Diff(want, clusters(&g, tt.in)); len(diff) > 0 {
+				t.Errorf("diff after Reset (-want +got):%s\n", diff)
+			}
+		})
+	}
+}
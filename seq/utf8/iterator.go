@@ -89,6 +89,14 @@ func (s *Iterable) Slice(lo, hi int) *Iterable {
 	return s
 }
 
+// Count returns the number of runes remaining in the current range of s.
+func (s *Iterable) Count() int {
+	if s == nil || s.Iterator == nil {
+		return 0
+	}
+	return int(s.end - s.pos)
+}
+
 // Next returns the next Rune in s.
 //
 // If there are no elements remaining in s, returns a Rune r such that
@@ -124,7 +132,8 @@ func (s *Iterable) Len() (n int) {
 }
 
 // GlyphCount scans the current range and counts the number of runes that
-// are not within any escape sequence.
+// are not within any escape sequence. See type Escape for the recognized
+// escape sequences.
 //
 // Once scanning completes, the receiver's internal indices are reset to their
 // original value from when the method was called.
@@ -149,22 +158,48 @@ func (s *Iterable) Len() (n int) {
 //	with the escape sequence's bounds, the final 2 runes of the escape sequence
 //	was erroneously counted as ordinary runes in the third Iterator.
 func (s *Iterable) GlyphCount() (count int) {
+	return s.glyphs(false)
+}
+
+// Width scans the current range and sums the number of columns occupied by each
+// rune that is not within any escape sequence. See function Width for the
+// number of columns occupied by each rune.
+//
+// Once scanning completes, the receiver's internal indices are reset to their
+// original value from when the method was called.
+//
+// Width is subject to the same limitation as GlyphCount regarding escape
+// sequences that begin before the Iterator's first element.
+func (s *Iterable) Width() (width int) {
+	return s.glyphs(true)
+}
+
+// glyphs scans the current range and counts the runes that are not within any
+// escape sequence, or sums the number of columns they occupy if width is true.
+//
+// Once scanning completes, the receiver's internal indices are reset to their
+// original value from when the method was called.
+func (s *Iterable) glyphs(width bool) (n int) {
+	if s == nil || s.Iterator == nil {
+		return
+	}
 	// Capture head/tail and restore after scanning
 	defer func(p *Iterable, h, t uint32) {
 		p.pos = h
 		p.end = t
 	}(s, s.pos, s.end)
-	var esc, err bool
-	for !err {
-		switch r := s.Next(); {
-		case r == nil || *r == invalid: // (TBD) Precedes case esc! Is this right?
-			err = true
-		case esc:
-			esc = (*r < 'a' || 'z' < *r) && (*r < 'A' || 'Z' < *r)
-		case *r == 0x1B: // ESC (27)
-			esc = true
+	var esc Escape
+	for {
+		r := s.Next()
+		if *r == invalid {
+			break
+		}
+		switch {
+		case esc.Scan(rune(*r)):
+		case width:
+			n += r.Width()
 		default:
-			count++
+			n++
 		}
 	}
 	return
@@ -0,0 +1,326 @@
+//go:build ignore
+// +build ignore
+
+// Command mktables generates the rune width and grapheme cluster break tables
+// from the Unicode Character Database (UCD) files of an explicit Unicode version.
+//
+// The UCD files are downloaded from unicode.org, or read from a local copy of
+// the UCD directory with flag -ucd, which must have the same layout as the
+// published directory (e.g., emoji/emoji-data.txt).
+//
+// Usage:
+//
+//	go run mktables.go -unicode 14.0.0 -table width [-ucd dir]
+//	go run mktables.go -unicode 14.0.0 -table grapheme [-ucd dir]
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// UCD files read by mktables, relative to the UCD directory.
+const (
+	fileEastAsianWidth  = "EastAsianWidth.txt"
+	fileGeneralCategory = "extracted/DerivedGeneralCategory.txt"
+	fileGraphemeBreak   = "auxiliary/GraphemeBreakProperty.txt"
+	fileEmojiData       = "emoji/emoji-data.txt"
+)
+
+// urlFormat is the URL of a UCD file of a given Unicode version.
+const urlFormat = "https://www.unicode.org/Public/%s/ucd/%s"
+
+// maxRune is the maximum valid Unicode code point.
+const maxRune = 0x10FFFF
+
+var (
+	unicode = flag.String("unicode", "", "Unicode version of the UCD files (e.g., 14.0.0)")
+	table   = flag.String("table", "", "table to generate (width or grapheme)")
+	ucd     = flag.String("ucd", "", "local UCD directory (default: download from unicode.org)")
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("mktables: ")
+	flag.Parse()
+	if *unicode == "" {
+		log.Fatal("flag -unicode is required")
+	}
+	if os.Getenv("GOPACKAGE") == "" {
+		log.Fatal("required env variable undefined: GOPACKAGE")
+	}
+	var (
+		file string
+		body []byte
+	)
+	switch *table {
+	case "width":
+		file, body = "width_table.go", widthTable()
+	case "grapheme":
+		file, body = "grapheme_table.go", graphemeTable()
+	default:
+		log.Fatalf("unknown table: %q", *table)
+	}
+	src, err := format.Source(body)
+	if err != nil {
+		log.Fatalf("%s: %v", file, err)
+	}
+	if err := os.WriteFile(file, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// header returns the beginning of a generated Go source file with build
+// constraint tag.
+func header(tag string) *bytes.Buffer {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by mktables.go. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "//go:build %s\n// +build %s\n\n", tag, tag)
+	fmt.Fprintf(&b, "package %s\n\n", os.Getenv("GOPACKAGE"))
+	fmt.Fprintf(&b, "// Unicode version %s\n\n", *unicode)
+	return &b
+}
+
+// widthTable returns the Go source of the rune width tables. The tables can be
+// omitted on flash-constrained targets with build tag "nowcwidth", in which case
+// every printable rune is considered to occupy one column.
+func widthTable() []byte {
+	gc := parse(fileGeneralCategory)
+	eaw := parse(fileEastAsianWidth)
+
+	// Runes that occupy zero columns: nonspacing and enclosing combining marks,
+	// format characters (except SOFT HYPHEN), Hangul Jamo medial vowels and final
+	// consonants, and ZERO WIDTH SPACE.
+	zero := make([]bool, maxRune+1)
+	for _, p := range gc {
+		switch p.value {
+		case "Mn", "Me", "Cf":
+			fill(zero, p, true)
+		}
+	}
+	zero[0x00AD] = false
+	fill(zero, property{0x1160, 0x11FF, ""}, true)
+	zero[0x200B] = true
+
+	// Runes that occupy two columns: East Asian Wide and Fullwidth. Runes that are
+	// not listed in EastAsianWidth.txt default to Wide in the blocks reserved for
+	// CJK ideographs, as described in the file's header.
+	wide := make([]bool, maxRune+1)
+	for _, p := range []property{
+		{0x3400, 0x4DBF, "W"},
+		{0x4E00, 0x9FFF, "W"},
+		{0xF900, 0xFAFF, "W"},
+		{0x20000, 0x2FFFD, "W"},
+		{0x30000, 0x3FFFD, "W"},
+	} {
+		fill(wide, p, true)
+	}
+	for _, p := range eaw {
+		fill(wide, p, p.value == "W" || p.value == "F")
+	}
+
+	b := header("!nowcwidth")
+	fmt.Fprintf(b, "// width returns the number of columns occupied by r.\n")
+	fmt.Fprintf(b, "func width(r rune) int {\n")
+	fmt.Fprintf(b, "\tswitch {\n")
+	fmt.Fprintf(b, "\tcase inRange(r, zeroWidth[:]):\n\t\treturn 0\n")
+	fmt.Fprintf(b, "\tcase inRange(r, wideWidth[:]):\n\t\treturn 2\n")
+	fmt.Fprintf(b, "\t}\n\treturn 1\n}\n\n")
+	for _, t := range []struct {
+		name, desc string
+		set        []bool
+	}{
+		{"zeroWidth", "occupy zero columns", zero},
+		{"wideWidth", "occupy two columns", wide},
+	} {
+		fmt.Fprintf(b, "// %s contains the ranges of runes that %s.\n", t.name, t.desc)
+		fmt.Fprintf(b, "var %s = [...]runeRange{\n", t.name)
+		for _, r := range ranges(t.set, func(v bool) bool { return v }) {
+			fmt.Fprintf(b, "\t{0x%04X, 0x%04X},\n", r.lo, r.hi)
+		}
+		fmt.Fprintf(b, "}\n\n")
+	}
+	return b.Bytes()
+}
+
+// graphemeTable returns the Go source of the Grapheme_Cluster_Break property
+// table. The table can be omitted on flash-constrained targets with build tag
+// "nographeme", in which case every rune other than CR+LF is considered its own
+// grapheme cluster.
+func graphemeTable() []byte {
+	// Map each Grapheme_Cluster_Break property value to its Go constant.
+	name := map[string]string{
+		"CR":                    "graphemeCR",
+		"LF":                    "graphemeLF",
+		"Control":               "graphemeControl",
+		"Extend":                "graphemeExtend",
+		"ZWJ":                   "graphemeZWJ",
+		"Regional_Indicator":    "graphemeRegionalIndicator",
+		"Prepend":               "graphemePrepend",
+		"SpacingMark":           "graphemeSpacingMark",
+		"L":                     "graphemeL",
+		"V":                     "graphemeV",
+		"T":                     "graphemeT",
+		"LV":                    "graphemeLV",
+		"LVT":                   "graphemeLVT",
+		"Extended_Pictographic": "graphemeExtendedPictographic",
+	}
+	prop := make([]string, maxRune+1)
+	for _, p := range parse(fileGraphemeBreak) {
+		if _, ok := name[p.value]; !ok {
+			log.Fatalf("%s: unknown Grapheme_Cluster_Break value: %s",
+				fileGraphemeBreak, p.value)
+		}
+		fill(prop, p, p.value)
+	}
+	// Property Extended_Pictographic is assigned only to runes whose
+	// Grapheme_Cluster_Break property is otherwise Other.
+	for _, p := range parse(fileEmojiData) {
+		if p.value != "Extended_Pictographic" {
+			continue
+		}
+		for r := p.lo; r <= p.hi; r++ {
+			if prop[r] == "" {
+				prop[r] = p.value
+			}
+		}
+	}
+
+	b := header("!nographeme")
+	fmt.Fprintf(b, "// graphemeProperty returns the Grapheme_Cluster_Break property of r.\n")
+	fmt.Fprintf(b, "func graphemeProperty(r rune) graphemeProp {\n")
+	fmt.Fprintf(b, "\tlo, hi := 0, len(graphemeTable)\n")
+	fmt.Fprintf(b, "\tfor lo < hi {\n")
+	fmt.Fprintf(b, "\t\tm := int(uint(lo+hi) >> 1)\n")
+	fmt.Fprintf(b, "\t\tswitch {\n")
+	fmt.Fprintf(b, "\t\tcase r < graphemeTable[m].lo:\n\t\t\thi = m\n")
+	fmt.Fprintf(b, "\t\tcase r > graphemeTable[m].hi:\n\t\t\tlo = m + 1\n")
+	fmt.Fprintf(b, "\t\tdefault:\n\t\t\treturn graphemeTable[m].prop\n")
+	fmt.Fprintf(b, "\t\t}\n\t}\n\treturn graphemeOther\n}\n\n")
+	fmt.Fprintf(b, "// graphemeTable contains the ranges of runes with a Grapheme_Cluster_Break\n")
+	fmt.Fprintf(b, "// property other than Other.\n")
+	fmt.Fprintf(b, "var graphemeTable = [...]struct {\n\tlo, hi rune\n\tprop   graphemeProp\n}{\n")
+	for _, r := range ranges(prop, func(v string) bool { return v != "" }) {
+		fmt.Fprintf(b, "\t{0x%04X, 0x%04X, %s},\n", r.lo, r.hi, name[r.value])
+	}
+	fmt.Fprintf(b, "}\n\n")
+	return b.Bytes()
+}
+
+// property is an inclusive range of runes with the same property value.
+type property struct {
+	lo, hi int
+	value  string
+}
+
+// fill sets the elements of s indexed by the runes in p to v.
+func fill[T any](s []T, p property, v T) {
+	for r := p.lo; r <= p.hi; r++ {
+		s[r] = v
+	}
+}
+
+// ranges returns the maximal ranges of consecutive runes r with equal values
+// s[r] for which keep(s[r]) is true.
+func ranges[T comparable](s []T, keep func(T) bool) (out []struct {
+	lo, hi int
+	value  T
+}) {
+	for r, v := range s {
+		if !keep(v) {
+			continue
+		}
+		if n := len(out); n > 0 && out[n-1].hi == r-1 && out[n-1].value == v {
+			out[n-1].hi = r
+			continue
+		}
+		out = append(out, struct {
+			lo, hi int
+			value  T
+		}{r, r, v})
+	}
+	return
+}
+
+// parse returns the properties listed in the UCD file with the given path,
+// sorted by rune. Each line of the file has the form:
+//
+//	0000..001F    ; Control # Cc  [32] <control-0000>..<control-001F>
+func parse(path string) (props []property) {
+	rc := open(path)
+	defer rc.Close()
+	s := bufio.NewScanner(rc)
+	for n := 1; s.Scan(); n++ {
+		line := s.Text()
+		// The first line of most UCD files names the file and its Unicode version,
+		// e.g., "# EastAsianWidth-14.0.0.txt".
+		base := "# " + strings.TrimSuffix(filepath.Base(path), ".txt") + "-"
+		if n == 1 && strings.HasPrefix(line, base) && line != base+*unicode+".txt" {
+			log.Fatalf("%s: not Unicode version %s: %q", path, *unicode, line)
+		}
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		field := strings.Split(line, ";")
+		if len(field) < 2 {
+			log.Fatalf("%s:%d: invalid line: %q", path, n, s.Text())
+		}
+		lo, hi, ok := strings.Cut(strings.TrimSpace(field[0]), "..")
+		if !ok {
+			hi = lo
+		}
+		p := property{value: strings.TrimSpace(field[1])}
+		p.lo, p.hi = codePoint(path, n, lo), codePoint(path, n, hi)
+		props = append(props, p)
+	}
+	if err := s.Err(); err != nil {
+		log.Fatalf("%s: %v", path, err)
+	}
+	sort.Slice(props, func(i, j int) bool { return props[i].lo < props[j].lo })
+	return
+}
+
+// codePoint returns the rune encoded as hexadecimal string s on line n of the
+// UCD file with the given path.
+func codePoint(path string, n int, s string) int {
+	r, err := strconv.ParseUint(s, 16, 32)
+	if err != nil || r > uint64(maxRune) {
+		log.Fatalf("%s:%d: invalid code point: %q", path, n, s)
+	}
+	return int(r)
+}
+
+// open returns a reader of the UCD file with the given path, either from the
+// local UCD directory or from unicode.org.
+func open(path string) io.ReadCloser {
+	if *ucd != "" {
+		f, err := os.Open(filepath.Join(*ucd, filepath.FromSlash(path)))
+		if err != nil {
+			log.Fatal(err)
+		}
+		return f
+	}
+	url := fmt.Sprintf(urlFormat, *unicode, path)
+	resp, err := http.Get(url)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.Fatalf("%s: %s", url, resp.Status)
+	}
+	return resp.Body
+}
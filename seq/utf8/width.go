@@ -0,0 +1,57 @@
+package utf8
+
+import "unicode/utf8"
+
+//go:generate go run mktables.go -unicode 14.0.0 -table width
+
+// runeRange defines an inclusive range of runes.
+type runeRange struct{ lo, hi rune }
+
+// inRange returns true if and only if r is in one of the ranges in t, which
+// must be sorted and non-overlapping.
+func inRange(r rune, t []runeRange) bool {
+	if len(t) == 0 || r < t[0].lo || r > t[len(t)-1].hi {
+		return false
+	}
+	lo, hi := 0, len(t)
+	for lo < hi {
+		m := int(uint(lo+hi) >> 1)
+		switch {
+		case r < t[m].lo:
+			hi = m
+		case r > t[m].hi:
+			lo = m + 1
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// Width returns the number of columns occupied by r on a terminal display.
+//
+// Control characters and invalid runes occupy zero columns. Combining marks and
+// other zero-width runes occupy zero columns, and East Asian wide and fullwidth
+// runes, which include most emoji, occupy two columns. All other runes occupy
+// one column.
+//
+// With build tag "nowcwidth", the tables of zero-width and wide runes are not
+// compiled, and all printable runes are considered to occupy one column.
+func Width(r rune) int {
+	switch {
+	case r < 0x20, 0x7F <= r && r < 0xA0, r > utf8.MaxRune:
+		return 0
+	case r < 0x0300:
+		return 1
+	}
+	return width(r)
+}
+
+// Width returns the number of columns occupied by r on a terminal display.
+// See function Width for details.
+func (r *Rune) Width() int {
+	if r == nil {
+		return 0
+	}
+	return Width(rune(*r))
+}
@@ -0,0 +1,9 @@
+//go:build nowcwidth
+// +build nowcwidth
+
+package utf8
+
+// width returns the number of columns occupied by r.
+func width(r rune) int {
+	return 1
+}
@@ -0,0 +1,7 @@
+//go:build !nowcwidth
+// +build !nowcwidth
+
+package utf8
+
+// nowcwidth is true if and only if the width tables are not compiled.
+const nowcwidth = false
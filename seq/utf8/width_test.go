@@ -0,0 +1,55 @@
+package utf8
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWidth(t *testing.T) {
+	t.Parallel()
+	for name, tt := range map[string]struct {
+		r       rune
+		want    int
+		wantTag int // Width with build tag "nowcwidth"
+	}{
+		"ascii":          {r: 'a', want: 1, wantTag: 1},
+		"space":          {r: ' ', want: 1, wantTag: 1},
+		"tilde":          {r: '~', want: 1, wantTag: 1},
+		"nul":            {r: 0x00, want: 0, wantTag: 0},
+		"escape":         {r: 0x1B, want: 0, wantTag: 0},
+		"delete":         {r: 0x7F, want: 0, wantTag: 0},
+		"c1-csi":         {r: 0x9B, want: 0, wantTag: 0},
+		"invalid":        {r: 0x110000, want: 0, wantTag: 0},
+		"latin":          {r: 'é', want: 1, wantTag: 1},
+		"combining":      {r: 0x0301, want: 0, wantTag: 1},
+		"hebrew-point":   {r: 0x05B0, want: 0, wantTag: 1},
+		"zero-width-sp":  {r: 0x200B, want: 0, wantTag: 1},
+		"zero-width-j":   {r: 0x200D, want: 0, wantTag: 1},
+		"variation-sel":  {r: 0xFE0F, want: 0, wantTag: 1},
+		"cjk":            {r: '漢', want: 2, wantTag: 1},
+		"hiragana":       {r: 'あ', want: 2, wantTag: 1},
+		"hangul":         {r: '한', want: 2, wantTag: 1},
+		"fullwidth":      {r: 'Ａ', want: 2, wantTag: 1},
+		"halfwidth-kana": {r: 'ｱ', want: 1, wantTag: 1},
+		"emoji":          {r: '😀', want: 2, wantTag: 1},
+		"emoji-rocket":   {r: '🚀', want: 2, wantTag: 1},
+		"box-drawing":    {r: '─', want: 1, wantTag: 1},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			want := tt.want
+			if nowcwidth {
+				want = tt.wantTag
+			}
+			if diff := cmp.Diff(want, Width(tt.r)); len(diff) > 0 {
+				t.Errorf("diff (-want +got):%s\n", diff)
+			}
+			r := Rune(tt.r)
+			if diff := cmp.Diff(want, r.Width()); len(diff) > 0 {
+				t.Errorf("diff Rune.Width (-want +got):%s\n", diff)
+			}
+		})
+	}
+}
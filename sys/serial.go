@@ -0,0 +1,91 @@
+package sys
+
+import "time"
+
+// Parity is the parity bit sent with each character over a serial line.
+type Parity uint8
+
+// Constant values of enumerated type Parity.
+const (
+	ParityNone Parity = iota // No parity bit
+	ParityOdd                // Odd number of set bits, including the parity bit
+	ParityEven               // Even number of set bits, including the parity bit
+)
+
+// Flow is a bitmask of the flow control methods of a serial line.
+type Flow uint8
+
+// FlowNone indicates no flow control.
+const FlowNone Flow = 0
+
+// Constant bits of enumerated type Flow.
+const (
+	FlowHardware Flow = 1 << iota // RTS/CTS
+	FlowSoftware                  // XON/XOFF
+)
+
+// maxReadTimeout is the longest timeout between bytes that the terminal driver
+// supports, in tenths of a second (VTIME).
+const maxReadTimeout = 255 * 100 * time.Millisecond
+
+// The following methods configure the serial line of the device connected to
+// f's file descriptor, e.g., a USB serial adapter (/dev/ttyUSB0).
+//
+// Each method modifies the current configuration of the device. The state read
+// during MakeFdio or Save is not modified, so that Restore reverts all changes.
+// Each method returns false if the configuration is not supported, or could not
+// be written. Serial lines are not supported on Windows.
+
+// SetBaud sets the input and output baud rates of the serial line, e.g., 115200.
+// On Linux, rate must be one of the standard rates (50 to 4000000).
+func (f *Fdio) SetBaud(rate int) bool {
+	if rate <= 0 || uint64(rate) > 1<<32-1 {
+		return false
+	}
+	return f.setBaud(uint32(rate))
+}
+
+// SetCharSize sets the number of data bits per character, in the range 5–8.
+func (f *Fdio) SetCharSize(bits int) bool {
+	return f.setCharSize(bits)
+}
+
+// SetParity sets the parity bit sent and checked with each character.
+func (f *Fdio) SetParity(p Parity) bool {
+	if p > ParityEven {
+		return false
+	}
+	return f.setParity(p)
+}
+
+// SetStopBits sets the number of stop bits sent with each character, 1 or 2.
+func (f *Fdio) SetStopBits(n int) bool {
+	if n != 1 && n != 2 {
+		return false
+	}
+	return f.setStopBits(n)
+}
+
+// SetFlow sets the flow control methods of the serial line. Methods not set in
+// fl are disabled.
+func (f *Fdio) SetFlow(fl Flow) bool {
+	if fl&^(FlowHardware|FlowSoftware) != 0 {
+		return false
+	}
+	return f.setFlow(fl)
+}
+
+// SetReadTimeout sets the conditions under which a read from the device returns
+// in raw mode: once min bytes are received, or once timeout has elapsed since
+// the last byte was received. A timeout of 0 waits indefinitely for min bytes,
+// and a min of 0 waits at most timeout for the first byte.
+//
+// The timeout is rounded up to tenths of a second, and must not exceed 25.5s.
+// The min must not exceed 255.
+func (f *Fdio) SetReadTimeout(min int, timeout time.Duration) bool {
+	if min < 0 || min > 255 || timeout < 0 || timeout > maxReadTimeout {
+		return false
+	}
+	tenths := (timeout + 100*time.Millisecond - 1) / (100 * time.Millisecond)
+	return f.setReadTimeout(uint8(min), uint8(tenths))
+}
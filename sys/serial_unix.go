@@ -0,0 +1,57 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package sys
+
+import (
+	"github.com/ardnew/embedit/sys/unix"
+)
+
+// current reads the current state of the terminal connected to f's file
+// descriptor, without replacing the state stored for Restore.
+func (f *Fdio) current() (ts unix.Termios, ok bool) {
+	if !f.valid {
+		return ts, false
+	}
+	return unix.GetTermios(f.fd)
+}
+
+func (f *Fdio) setBaud(rate uint32) bool {
+	ts, ok := f.current()
+	if ok {
+		ts, ok = ts.Speed(rate)
+	}
+	return ok && f.write(ts)
+}
+
+func (f *Fdio) setCharSize(bits int) bool {
+	ts, ok := f.current()
+	if ok {
+		ts, ok = ts.CharSize(bits)
+	}
+	return ok && f.write(ts)
+}
+
+func (f *Fdio) setParity(p Parity) bool {
+	ts, ok := f.current()
+	return ok && f.write(ts.Parity(p != ParityNone, p == ParityOdd))
+}
+
+func (f *Fdio) setStopBits(n int) bool {
+	ts, ok := f.current()
+	return ok && f.write(ts.StopBits(n == 2))
+}
+
+func (f *Fdio) setFlow(fl Flow) bool {
+	ts, ok := f.current()
+	if ok {
+		ts = ts.HardwareFlow(fl&FlowHardware != 0)
+		ts = ts.SoftwareFlow(fl&FlowSoftware != 0)
+	}
+	return ok && f.write(ts)
+}
+
+func (f *Fdio) setReadTimeout(min, tenths uint8) bool {
+	ts, ok := f.current()
+	return ok && f.write(ts.ReadTimeout(min, tenths))
+}
@@ -0,0 +1,11 @@
+//go:build windows
+// +build windows
+
+package sys
+
+func (f *Fdio) setBaud(rate uint32) bool              { return false }
+func (f *Fdio) setCharSize(bits int) bool             { return false }
+func (f *Fdio) setParity(p Parity) bool               { return false }
+func (f *Fdio) setStopBits(n int) bool                { return false }
+func (f *Fdio) setFlow(fl Flow) bool                  { return false }
+func (f *Fdio) setReadTimeout(min, tenths uint8) bool { return false }
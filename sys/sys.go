@@ -13,6 +13,14 @@ func MakeFdio(fd int) Fdio {
 	return makeFdio(fd)
 }
 
+// IsTerminal returns true if and only if the given file descriptor is connected
+// to a terminal, e.g., false if standard input is redirected from a file or a
+// pipe.
+func IsTerminal(fd int) bool {
+	f := makeFdio(fd)
+	return f.Valid()
+}
+
 // Fd returns the file descriptor to which a terminal was configured to connect.
 func (f *Fdio) Fd() int {
 	return f.fd
@@ -24,6 +32,13 @@ func (f *Fdio) Valid() bool {
 	return f.valid
 }
 
+// Size returns the width and height, in columns and rows, of the window of the
+// terminal connected to f's file descriptor. Returns ok=false if the size could
+// not be read, e.g., if the file descriptor is not a terminal.
+func (f *Fdio) Size() (width, height int, ok bool) {
+	return f.size()
+}
+
 // Save stores the current state of the terminal connected to f's file
 // descriptor. This state can later be restored via receiver's Restore method.
 //
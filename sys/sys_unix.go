@@ -26,6 +26,14 @@ func (f *Fdio) write(ts unix.Termios) bool {
 	return unix.SetTermios(f.fd, ts)
 }
 
+func (f *Fdio) size() (width, height int, ok bool) {
+	ws, ok := unix.GetWinsize(f.fd)
+	if !ok || ws.Col == 0 || ws.Row == 0 {
+		return 0, 0, false
+	}
+	return int(ws.Col), int(ws.Row), true
+}
+
 func (f *Fdio) raw() bool {
 	// Using f.read() here will cause f.restore() to always restore to the state
 	// prior to f.raw() being called, instead of using the initial state obtained
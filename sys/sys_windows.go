@@ -23,6 +23,15 @@ func (f *Fdio) write(mode uint32) bool {
 	return windows.SetConsoleMode(windows.Handle(f.fd), mode) == nil
 }
 
+func (f *Fdio) size() (width, height int, ok bool) {
+	var info windows.ConsoleScreenBufferInfo
+	if windows.GetConsoleScreenBufferInfo(windows.Handle(f.fd), &info) != nil {
+		return 0, 0, false
+	}
+	w := info.Window
+	return int(w.Right-w.Left) + 1, int(w.Bottom-w.Top) + 1, true
+}
+
 func (f *Fdio) raw() bool {
 	// Using f.read() here will cause f.restore() to always restore to the state
 	// prior to f.raw() being called, instead of using the initial state obtained
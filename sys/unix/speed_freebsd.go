@@ -0,0 +1,15 @@
+//go:build freebsd
+// +build freebsd
+
+package unix
+
+// setSpeed sets the input and output baud rates of t to rate. The speed fields
+// of Termios contain the rate itself, so any rate supported by the device
+// driver is accepted.
+func (t *Termios) setSpeed(rate uint32) bool {
+	if rate == 0 {
+		return false
+	}
+	t.Ispeed, t.Ospeed = rate, rate
+	return true
+}
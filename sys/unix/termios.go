@@ -31,6 +31,89 @@ func (t Termios) Raw() Termios {
 	return t
 }
 
+// Speed returns a copy of the receiver Termios with its input and output baud
+// rates set to rate. Returns ok=false if rate is not supported.
+func (t Termios) Speed(rate uint32) (Termios, bool) {
+	ok := t.setSpeed(rate)
+	return t, ok
+}
+
+// CharSize returns a copy of the receiver Termios with the given number of data
+// bits per character. Returns ok=false if bits is not in the range 5–8.
+func (t Termios) CharSize(bits int) (Termios, bool) {
+	if bits < 5 || bits > 8 {
+		return t, false
+	}
+	t.Cflag &^= unix.CSIZE
+	switch bits {
+	case 5:
+		t.Cflag |= unix.CS5
+	case 6:
+		t.Cflag |= unix.CS6
+	case 7:
+		t.Cflag |= unix.CS7
+	case 8:
+		t.Cflag |= unix.CS8
+	}
+	return t, true
+}
+
+// Parity returns a copy of the receiver Termios that generates and checks a
+// parity bit with each character if enable is true, using odd parity if odd is
+// true, or even parity otherwise.
+func (t Termios) Parity(enable, odd bool) Termios {
+	t.Cflag &^= unix.PARENB | unix.PARODD
+	t.Iflag &^= unix.INPCK
+	if enable {
+		t.Cflag |= unix.PARENB
+		t.Iflag |= unix.INPCK
+		if odd {
+			t.Cflag |= unix.PARODD
+		}
+	}
+	return t
+}
+
+// StopBits returns a copy of the receiver Termios that sends two stop bits with
+// each character if two is true, or one stop bit otherwise.
+func (t Termios) StopBits(two bool) Termios {
+	t.Cflag &^= unix.CSTOPB
+	if two {
+		t.Cflag |= unix.CSTOPB
+	}
+	return t
+}
+
+// HardwareFlow returns a copy of the receiver Termios with RTS/CTS flow control
+// enabled or disabled.
+func (t Termios) HardwareFlow(enable bool) Termios {
+	t.Cflag &^= unix.CRTSCTS
+	if enable {
+		t.Cflag |= unix.CRTSCTS
+	}
+	return t
+}
+
+// SoftwareFlow returns a copy of the receiver Termios with XON/XOFF flow control
+// of both input and output enabled or disabled.
+func (t Termios) SoftwareFlow(enable bool) Termios {
+	t.Iflag &^= unix.IXON | unix.IXOFF | unix.IXANY
+	if enable {
+		t.Iflag |= unix.IXON | unix.IXOFF
+	}
+	return t
+}
+
+// ReadTimeout returns a copy of the receiver Termios in which a read in
+// noncanonical mode returns once min bytes are received, or once time tenths of
+// a second have elapsed since the last byte was received (see VMIN and VTIME in
+// the termios(3) manpage).
+func (t Termios) ReadTimeout(min, time uint8) Termios {
+	t.Cc[unix.VMIN] = min
+	t.Cc[unix.VTIME] = time
+	return t
+}
+
 func SetTermios(fd int, value Termios) (ok bool) {
 	ok = ioctl(fd, setTermios, uintptr(unsafe.Pointer(&value.Termios))) == retOK
 	runtime.KeepAlive(value)
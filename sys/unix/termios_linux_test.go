@@ -0,0 +1,219 @@
+package unix
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/sys/unix"
+)
+
+// flags contains the fields of a Termios modified by its builders.
+type flags struct {
+	Iflag, Cflag   uint32
+	Ispeed, Ospeed uint32
+	Vmin, Vtime    uint8
+}
+
+func flagsOf(t Termios) flags {
+	return flags{
+		Iflag:  t.Iflag,
+		Cflag:  t.Cflag,
+		Ispeed: t.Ispeed,
+		Ospeed: t.Ospeed,
+		Vmin:   t.Cc[unix.VMIN],
+		Vtime:  t.Cc[unix.VTIME],
+	}
+}
+
+// termios returns a Termios with every setting changed by a builder in a state
+// the builder must clear or replace.
+func termios() Termios {
+	var t Termios
+	t.Iflag = unix.INPCK | unix.IXON | unix.IXANY | unix.ICRNL
+	t.Cflag = unix.B9600 | unix.CIBAUD | unix.CS5 | unix.PARENB | unix.PARODD |
+		unix.CSTOPB | unix.CRTSCTS | unix.CREAD
+	t.Ispeed, t.Ospeed = unix.B9600, unix.B9600
+	t.Cc[unix.VMIN], t.Cc[unix.VTIME] = 1, 0
+	return t
+}
+
+func TestTermios_Speed(t *testing.T) {
+	t.Parallel()
+	base := termios()
+	other := base.Cflag &^ (unix.CBAUD | unix.CIBAUD)
+	type want struct {
+		flags flags
+		ok    bool
+	}
+	for name, tt := range map[string]struct {
+		rate uint32
+		want want
+	}{
+		"50": {
+			rate: 50,
+			want: want{ok: true, flags: flags{
+				Iflag: base.Iflag, Cflag: other | unix.B50,
+				Ispeed: unix.B50, Ospeed: unix.B50, Vmin: 1,
+			}},
+		},
+		"115200": {
+			rate: 115200,
+			want: want{ok: true, flags: flags{
+				Iflag: base.Iflag, Cflag: other | unix.B115200,
+				Ispeed: unix.B115200, Ospeed: unix.B115200, Vmin: 1,
+			}},
+		},
+		"4000000": {
+			rate: 4000000,
+			want: want{ok: true, flags: flags{
+				Iflag: base.Iflag, Cflag: other | unix.B4000000,
+				Ispeed: unix.B4000000, Ospeed: unix.B4000000, Vmin: 1,
+			}},
+		},
+		"unsupported-0": {
+			rate: 0,
+			want: want{flags: flagsOf(base)},
+		},
+		"unsupported-14400": {
+			rate: 14400,
+			want: want{flags: flagsOf(base)},
+		},
+		"unsupported-bits": {
+			// The value of a Cflag constant is not a baud rate.
+			rate: unix.B115200,
+			want: want{flags: flagsOf(base)},
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := base.Speed(tt.rate)
+			if diff := cmp.Diff(tt.want, want{flags: flagsOf(got), ok: ok}, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("Speed() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTermios_CharSize(t *testing.T) {
+	t.Parallel()
+	base := termios()
+	other := base.Cflag &^ unix.CSIZE
+	for name, tt := range map[string]struct {
+		bits  int
+		cflag uint32
+		ok    bool
+	}{
+		"4": {bits: 4, cflag: base.Cflag},
+		"5": {bits: 5, cflag: other | unix.CS5, ok: true},
+		"6": {bits: 6, cflag: other | unix.CS6, ok: true},
+		"7": {bits: 7, cflag: other | unix.CS7, ok: true},
+		"8": {bits: 8, cflag: other | unix.CS8, ok: true},
+		"9": {bits: 9, cflag: base.Cflag},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := base.CharSize(tt.bits)
+			if ok != tt.ok {
+				t.Errorf("CharSize() ok = %v, want %v", ok, tt.ok)
+			}
+			want := flagsOf(base)
+			want.Cflag = tt.cflag
+			if diff := cmp.Diff(want, flagsOf(got)); diff != "" {
+				t.Errorf("CharSize() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTermios_Flags(t *testing.T) {
+	t.Parallel()
+	base := termios()
+	for name, tt := range map[string]struct {
+		build        func(Termios) Termios
+		iset, iclear uint32 // Iflag bits that must be set or cleared
+		cset, cclear uint32 // Cflag bits that must be set or cleared
+	}{
+		"parity-none": {
+			build:  func(t Termios) Termios { return t.Parity(false, false) },
+			iclear: unix.INPCK,
+			cclear: unix.PARENB | unix.PARODD,
+		},
+		"parity-none-odd": {
+			build:  func(t Termios) Termios { return t.Parity(false, true) },
+			iclear: unix.INPCK,
+			cclear: unix.PARENB | unix.PARODD,
+		},
+		"parity-even": {
+			build:  func(t Termios) Termios { return t.Parity(true, false) },
+			iset:   unix.INPCK,
+			cset:   unix.PARENB,
+			cclear: unix.PARODD,
+		},
+		"parity-odd": {
+			build: func(t Termios) Termios { return t.Parity(true, true) },
+			iset:  unix.INPCK,
+			cset:  unix.PARENB | unix.PARODD,
+		},
+		"stop-bits-1": {
+			build:  func(t Termios) Termios { return t.StopBits(false) },
+			cclear: unix.CSTOPB,
+		},
+		"stop-bits-2": {
+			build: func(t Termios) Termios { return t.StopBits(true) },
+			cset:  unix.CSTOPB,
+		},
+		"hardware-flow-off": {
+			build:  func(t Termios) Termios { return t.HardwareFlow(false) },
+			cclear: unix.CRTSCTS,
+		},
+		"hardware-flow-on": {
+			build: func(t Termios) Termios { return t.HardwareFlow(true) },
+			cset:  unix.CRTSCTS,
+		},
+		"software-flow-off": {
+			build:  func(t Termios) Termios { return t.SoftwareFlow(false) },
+			iclear: unix.IXON | unix.IXOFF | unix.IXANY,
+		},
+		"software-flow-on": {
+			build:  func(t Termios) Termios { return t.SoftwareFlow(true) },
+			iset:   unix.IXON | unix.IXOFF,
+			iclear: unix.IXANY,
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			want := flagsOf(base)
+			want.Iflag = want.Iflag&^tt.iclear | tt.iset
+			want.Cflag = want.Cflag&^tt.cclear | tt.cset
+			if diff := cmp.Diff(want, flagsOf(tt.build(base))); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTermios_ReadTimeout(t *testing.T) {
+	t.Parallel()
+	base := termios()
+	for name, tt := range map[string]struct {
+		min, time uint8
+	}{
+		"blocking":   {min: 1, time: 0},
+		"polling":    {min: 0, time: 0},
+		"timed":      {min: 0, time: 5},
+		"inter-byte": {min: 16, time: 2},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			want := flagsOf(base)
+			want.Vmin, want.Vtime = tt.min, tt.time
+			if diff := cmp.Diff(want, flagsOf(base.ReadTimeout(tt.min, tt.time))); diff != "" {
+				t.Errorf("ReadTimeout() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
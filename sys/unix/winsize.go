@@ -0,0 +1,20 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package unix
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Winsize is a wrapper for the type provided by golang.org/x/sys/unix.
+type Winsize struct {
+	unix.Winsize
+}
+
+func GetWinsize(fd int) (value Winsize, ok bool) {
+	ok = ioctl(fd, unix.TIOCGWINSZ, uintptr(unsafe.Pointer(&value.Winsize))) == retOK
+	return
+}
@@ -0,0 +1,258 @@
+package sys
+
+import (
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/ardnew/embedit/seq/ascii"
+	"github.com/ardnew/embedit/seq/utf8"
+)
+
+// SizeWatcher reads input from a terminal, and inserts a window size report into
+// the input each time the window of the terminal is resized.
+//
+// The report has the form ESC [ 8 ; <rows> ; <columns> t, which is received by
+// terminal.Terminal as a key.WindowSize event, and resizes its display while it
+// reads a line. The display is thereby only ever resized by the goroutine that
+// reads input, so that no synchronization is needed.
+//
+// A report is never inserted within an escape sequence or the UTF-8 encoding of
+// a rune of the input. If the input read so far ends with an incomplete escape
+// sequence or encoding, e.g., a key whose sequence was split across reads, the
+// report is inserted once it is complete, or once no more input is received
+// for escapeTimeout, e.g., after a lone ESC key.
+//
+// On Windows, the window size is never reported.
+type SizeWatcher struct {
+	f    *Fdio
+	r    io.Reader
+	sig  chan os.Signal
+	data chan chunk
+	done chan struct{}
+	quit chan struct{}
+	pend []byte      // Unread bytes of the last chunk
+	more bool        // pend refers to a chunk still owned by the reader
+	err  error       // Error received with the last chunk
+	dead time.Time   // Deadline for Read to receive input, if not zero
+	size bool        // The window was resized, and a report is not yet inserted
+	esc  utf8.Escape // Scans the input copied by Read for escape sequences
+	cont int         // Continuation bytes of a UTF-8 encoding not yet copied
+	rep  report      // Storage for the last report
+	out  []byte      // Unread bytes of the last report
+	num  ascii.Uint32
+}
+
+// escapeTimeout is the time to wait for the rest of an incomplete escape sequence
+// or encoding before a window size report is inserted anyway.
+const escapeTimeout = 100 * time.Millisecond
+
+// reportSize is the maximum length of a window size report.
+const reportSize = len("\x1b[8;4294967295;4294967295t")
+
+// report is the storage of a window size report.
+type report struct {
+	buf [reportSize]byte
+	len int
+}
+
+// Write appends the bytes of p to r, and returns io.ErrShortWrite if they do not
+// all fit.
+func (r *report) Write(p []byte) (n int, err error) {
+	n = copy(r.buf[r.len:], p)
+	if r.len += n; n < len(p) {
+		err = io.ErrShortWrite
+	}
+	return
+}
+
+// chunk is the result of a single read from the input device.
+type chunk struct {
+	p   []byte
+	err error
+}
+
+// WatchSize returns a SizeWatcher that reads input from r, which is usually the
+// file of f's file descriptor (e.g., os.Stdin), and reports the window size of
+// the terminal connected to f's file descriptor each time it changes.
+//
+// Reading from r continues in a separate goroutine, so that a resize is reported
+// while no input is received. Call Stop when the SizeWatcher is no longer used.
+func (f *Fdio) WatchSize(r io.Reader) *SizeWatcher {
+	w := &SizeWatcher{
+		f:    f,
+		r:    r,
+		sig:  make(chan os.Signal, 1),
+		data: make(chan chunk),
+		done: make(chan struct{}),
+		quit: make(chan struct{}),
+	}
+	notifyResize(w.sig)
+	go w.read()
+	return w
+}
+
+// Stop stops reporting the window size, and causes all subsequent reads to
+// return io.EOF.
+//
+// The goroutine reading from the input device ends once its pending read
+// returns, which may be never if no more input is received.
+func (w *SizeWatcher) Stop() {
+	select {
+	case <-w.quit:
+	default:
+		signal.Stop(w.sig)
+		close(w.quit)
+	}
+}
+
+// SetReadDeadline sets the deadline for Read to receive input. A zero value for
+// t means Read will not time out. SetReadDeadline always returns nil.
+func (w *SizeWatcher) SetReadDeadline(t time.Time) error {
+	w.dead = t
+	return nil
+}
+
+// Read copies up to len(p) bytes of input, or of a window size report, into p
+// and returns the number of bytes copied. Read blocks until input is received,
+// the window is resized, or the deadline set with SetReadDeadline passes, in
+// which case Read returns os.ErrDeadlineExceeded.
+func (w *SizeWatcher) Read(p []byte) (n int, err error) {
+	var expire, stale <-chan time.Time
+	for {
+		if w.size && w.idle() {
+			w.size = false
+			w.report()
+		}
+		if len(w.out) > 0 {
+			n = copy(p, w.out)
+			w.out = w.out[n:]
+			return
+		}
+		if len(w.pend) > 0 || w.more {
+			return w.input(p)
+		}
+		if expire == nil && !w.dead.IsZero() {
+			d := time.Until(w.dead)
+			if d <= 0 {
+				return 0, os.ErrDeadlineExceeded
+			}
+			timer := time.NewTimer(d)
+			defer timer.Stop()
+			expire = timer.C
+		}
+		if stale == nil && w.size && !w.idle() {
+			// The input ends with an incomplete escape sequence or encoding, and no
+			// more input is pending. Stop waiting for the rest of it if it does not
+			// arrive soon.
+			timer := time.NewTimer(escapeTimeout)
+			defer timer.Stop()
+			stale = timer.C
+		}
+		select {
+		case <-w.quit:
+			return 0, io.EOF
+		case <-expire:
+			return 0, os.ErrDeadlineExceeded
+		case <-stale:
+			w.esc.Reset()
+			w.cont = 0
+		case <-w.sig:
+			w.size = true
+		case c := <-w.data:
+			w.pend, w.more, w.err = c.p, true, c.err
+		}
+	}
+}
+
+// idle returns true if and only if the input copied by Read does not end with an
+// incomplete escape sequence or UTF-8 encoding.
+func (w *SizeWatcher) idle() bool {
+	return !w.esc.Active() && w.cont == 0
+}
+
+// input copies up to len(p) bytes of the last chunk into p and returns the
+// number of bytes copied. If a report is waiting for an escape sequence or
+// encoding to complete, no bytes following it are copied.
+func (w *SizeWatcher) input(p []byte) (n int, err error) {
+	if n = len(w.pend); n > len(p) {
+		n = len(p)
+	}
+	for i := 0; i < n; i++ {
+		switch b := w.pend[i]; {
+		case w.cont > 0 && b&0xC0 == 0x80:
+			w.cont--
+		case b < 0x80:
+			w.cont = 0
+			w.esc.Scan(rune(b))
+		default:
+			// Multibyte encodings are never part of an escape sequence.
+			w.cont = 0
+			switch {
+			case b >= 0xF0:
+				w.cont = 3
+			case b >= 0xE0:
+				w.cont = 2
+			case b >= 0xC0:
+				w.cont = 1
+			}
+			w.esc.Scan(0xFFFD)
+		}
+		if w.size && w.idle() {
+			n = i + 1
+			break
+		}
+	}
+	n = copy(p, w.pend[:n])
+	if w.pend = w.pend[n:]; len(w.pend) == 0 && w.more {
+		// Return the storage of the chunk to the reader.
+		w.more = false
+		err, w.err = w.err, nil
+		select {
+		case w.done <- struct{}{}:
+		case <-w.quit:
+		}
+	}
+	return
+}
+
+// report formats the window size report of the terminal in out. Nothing is
+// reported if the size could not be read.
+func (w *SizeWatcher) report() {
+	width, height, ok := w.f.Size()
+	if !ok {
+		return
+	}
+	w.rep.len = 0
+	_, _ = w.rep.Write([]byte{ascii.ESC, '[', '8', ';'})
+	w.num.Val = uint32(height)
+	_, _ = w.num.WriteTo(&w.rep)
+	_, _ = w.rep.Write([]byte{';'})
+	w.num.Val = uint32(width)
+	_, _ = w.num.WriteTo(&w.rep)
+	_, _ = w.rep.Write([]byte{'t'})
+	w.out = w.rep.buf[:w.rep.len]
+}
+
+// read reads from the input device until it returns an error or the watcher is
+// stopped. Each chunk read is retained until Read has copied all of it.
+func (w *SizeWatcher) read() {
+	var buf [256]byte
+	for {
+		n, err := w.r.Read(buf[:])
+		select {
+		case w.data <- chunk{p: buf[:n], err: err}:
+		case <-w.quit:
+			return
+		}
+		select {
+		case <-w.done:
+		case <-w.quit:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package sys
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/sys/unix"
+)
+
+// Events of TestSizeWatcher_Read other than input.
+const (
+	resize = ""      // Resize the window
+	stall  = "stall" // Receive no input for longer than escapeTimeout
+)
+
+// chunks is an input device that returns each string received in a single read.
+type chunks chan string
+
+func (c chunks) Read(p []byte) (n int, err error) {
+	return copy(p, <-c), nil
+}
+
+// openPTY returns the controller and the terminal of a new pseudoterminal with
+// a window of the given size.
+func openPTY(t *testing.T, width, height int) (ptm, pts *os.File) {
+	t.Helper()
+	ptm, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	if err != nil {
+		t.Skipf("pseudoterminal not available: %v", err)
+	}
+	t.Cleanup(func() { ptm.Close() })
+	if err = unix.IoctlSetPointerInt(int(ptm.Fd()), unix.TIOCSPTLCK, 0); err != nil {
+		t.Skipf("pseudoterminal not available: %v", err)
+	}
+	n, err := unix.IoctlGetInt(int(ptm.Fd()), unix.TIOCGPTN)
+	if err != nil {
+		t.Skipf("pseudoterminal not available: %v", err)
+	}
+	if pts, err = os.OpenFile("/dev/pts/"+strconv.Itoa(n), os.O_RDWR, 0); err != nil {
+		t.Skipf("pseudoterminal not available: %v", err)
+	}
+	t.Cleanup(func() { pts.Close() })
+	ws := unix.Winsize{Row: uint16(height), Col: uint16(width)}
+	if err = unix.IoctlSetWinsize(int(ptm.Fd()), unix.TIOCSWINSZ, &ws); err != nil {
+		t.Fatalf("IoctlSetWinsize() error = %v", err)
+	}
+	return
+}
+
+func TestSizeWatcher_Read(t *testing.T) {
+	t.Parallel()
+	const report = "\x1b[8;40;120t"
+	for name, tt := range map[string]struct {
+		events []string // Chunks of input, or resize
+		want   string
+	}{
+		"idle": {
+			events: []string{"ab", resize, "c"},
+			want:   "ab" + report + "c",
+		},
+		"resize-first": {
+			events: []string{resize, "a"},
+			want:   report + "a",
+		},
+		"split-csi": {
+			events: []string{"a\x1b[1", resize, ";5Ab"},
+			want:   "a\x1b[1;5A" + report + "b",
+		},
+		"split-ss3": {
+			events: []string{"\x1b", resize, "OPq"},
+			want:   "\x1bOP" + report + "q",
+		},
+		"split-paste-end": {
+			events: []string{"x\x1b[20", resize, "1~"},
+			want:   "x\x1b[201~" + report,
+		},
+		"split-rune": {
+			events: []string{"\xe6\xbc", resize, "\xa2a"},
+			want:   "\xe6\xbc\xa2" + report + "a",
+		},
+		"sequence-after-rune": {
+			events: []string{"\xc3\xa9\x1b", resize, "[D"},
+			want:   "\xc3\xa9\x1b[D" + report,
+		},
+		"lone-esc": {
+			// The report is inserted once no more input follows ESC.
+			events: []string{"a\x1b", resize, stall, "b"},
+			want:   "a\x1b" + report + "b",
+		},
+		"stale-rune": {
+			events: []string{"\xe6\xbc", resize, stall, "a"},
+			want:   "\xe6\xbc" + report + "a",
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			_, pts := openPTY(t, 120, 40)
+			f := MakeFdio(int(pts.Fd()))
+			in := make(chunks)
+			w := f.WatchSize(in)
+			t.Cleanup(w.Stop)
+			var got []byte
+			var p [4]byte
+			for _, ev := range tt.events {
+				wait := 10 * time.Millisecond
+				switch ev {
+				case resize:
+					w.sig <- unix.SIGWINCH
+				case stall:
+					wait = 2 * escapeTimeout
+				default:
+					in <- ev
+				}
+				// Read until no more input is received.
+				for {
+					_ = w.SetReadDeadline(time.Now().Add(wait))
+					n, err := w.Read(p[:])
+					got = append(got, p[:n]...)
+					if errors.Is(err, os.ErrDeadlineExceeded) {
+						break
+					}
+					if err != nil {
+						t.Fatalf("Read() error = %v", err)
+					}
+				}
+			}
+			if diff := cmp.Diff(tt.want, string(got)); diff != "" {
+				t.Errorf("Read() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
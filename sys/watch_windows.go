@@ -0,0 +1,10 @@
+//go:build windows
+// +build windows
+
+package sys
+
+import "os"
+
+// notifyResize does nothing, since Windows consoles report resize events as
+// console input records instead of signals.
+func notifyResize(c chan<- os.Signal) {}
@@ -7,9 +7,38 @@ type State byte
 const (
 	Active State = iota
 	Inactive
+	Rejected // Active, but all pasted text is discarded
 )
 
-// IsActive returns true if and only if s is Active.
+// IsActive returns true if and only if s is Active or Rejected.
 func (s State) IsActive() bool {
-	return s == Active
+	return s == Active || s == Rejected
+}
+
+// Newline defines how a newline in pasted text is handled.
+type Newline byte
+
+// Constant values of enumerated type Newline.
+const (
+	Split  Newline = iota // End the line; remaining text is read as new lines
+	Join                  // Replace each newline with a space
+	Reject                // Discard the entire paste
+)
+
+// Overflow defines how pasted text that does not fit in a line is handled.
+type Overflow byte
+
+// Constant values of enumerated type Overflow.
+const (
+	Truncate Overflow = iota // Discard the text that does not fit
+	Wrap                     // End the line; remaining text is read as new lines
+)
+
+// Policy defines how pasted text is inserted into a line.
+//
+// Regardless of Policy, control characters and escape sequences in pasted text
+// are always discarded, and each tab is replaced with a space.
+type Policy struct {
+	Newline  Newline
+	Overflow Overflow
 }
@@ -0,0 +1,92 @@
+package terminal
+
+import (
+	"io"
+
+	"github.com/ardnew/embedit/errors"
+	"github.com/ardnew/embedit/seq/ascii"
+	"github.com/ardnew/embedit/terminal/key"
+)
+
+// EnableCooked enables or disables reading plain lines of input, e.g., when the
+// input device is not a terminal but a file or a pipe (see sys.IsTerminal).
+//
+// When enabled, ReadLine reads the runes of each line up to LF or CRLF without
+// drawing the prompt, echoing input, or recognizing any control keys or escape
+// sequences. The line is then entered as if Enter were pressed, so that it is
+// received by the KeyHandler and added to history as usual.
+//
+// The input device is assumed to block until input is available. Once a read
+// returns no bytes, input is considered exhausted: ReadLine enters any partial
+// line that remains, and then returns io.EOF.
+func (t *Terminal) EnableCooked(enable bool) (wasEnabled bool) {
+	wasEnabled = t.cook
+	t.cook = enable
+	return
+}
+
+// readCooked reads and enters the next plain line of input.
+//
+// Runes that do not fit in the line are discarded up to the end of the line,
+// and ErrWriteOverflow is returned after the truncated line is entered.
+func (t *Terminal) readCooked() (err error) {
+	echo := t.display.Echo()
+	t.display.SetEcho(false)
+	defer t.display.SetEcho(echo)
+	l := t.Line()
+	full := false
+	for eol := false; !eol; {
+		lf, e := t.scanCooked(&full)
+		if e != nil {
+			return e
+		}
+		if !lf && l.RuneCount() == 0 {
+			return io.EOF
+		}
+		// The line is only added to history if it would have been echoed.
+		if eol, err = t.HandleEvent(key.MakeEvent(key.Enter)); eol && echo {
+			t.history.Add()
+		}
+		_, _ = t.Flush()
+		if err != nil || !lf {
+			break
+		}
+	}
+	if err == nil && full {
+		err = &errors.ErrWriteOverflow
+	}
+	return
+}
+
+// scanCooked appends the runes of input to the current line until LF, and
+// returns lf=true if and only if LF was received before input was exhausted.
+// A CR preceding LF is discarded. If the line is full, full is set to true and
+// the remaining runes are discarded.
+func (t *Terminal) scanCooked(full *bool) (lf bool, err error) {
+	l := t.Line()
+	cr := false // CR received, but not yet appended
+	for {
+		for t.in.Len() > 0 {
+			r, sz := t.in.ParseRune()
+			if sz == 0 {
+				break
+			}
+			if r == ascii.LF {
+				return true, nil
+			}
+			if cr && l.AppendRune(ascii.CR) != nil {
+				*full = true
+			}
+			if cr = r == ascii.CR; !cr && l.AppendRune(r) != nil {
+				*full = true
+			}
+		}
+		var n int
+		if n, err = t.Swell(); err != nil || n == 0 {
+			if cr && l.AppendRune(ascii.CR) != nil {
+				*full = true
+			}
+			return false, err
+		}
+	}
+}
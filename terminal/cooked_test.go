@@ -0,0 +1,112 @@
+package terminal
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/ardnew/embedit/config/limits"
+	"github.com/ardnew/embedit/errors"
+	"github.com/ardnew/embedit/terminal/key"
+)
+
+// recorder is a KeyHandler that records the text of each line entered.
+type recorder struct {
+	lines []string
+}
+
+func (r *recorder) HandleEvent(t *Terminal, ev *key.Event) (handled, eol bool, err error) {
+	if ev.Key() == key.Enter {
+		r.lines = append(r.lines, text(t))
+	}
+	return
+}
+
+func TestTerminal_ReadCooked(t *testing.T) {
+	t.Parallel()
+	long := strings.Repeat("x", limits.RunesPerLine+8)
+	type want struct {
+		lines []string
+		errs  []error // Errors returned by each ReadLine, up to and including io.EOF
+	}
+	for name, tt := range map[string]struct {
+		input []string
+		want  want
+	}{
+		"empty": {
+			want: want{errs: []error{io.EOF}},
+		},
+		"lf": {
+			input: []string{"ab\ncd\n"},
+			want:  want{lines: []string{"ab", "cd"}, errs: []error{nil, nil, io.EOF}},
+		},
+		"crlf": {
+			input: []string{"ab\r\ncd\r\n"},
+			want:  want{lines: []string{"ab", "cd"}, errs: []error{nil, nil, io.EOF}},
+		},
+		"crlf-split": {
+			input: []string{"ab\r", "\ncd\r", "\n"},
+			want:  want{lines: []string{"ab", "cd"}, errs: []error{nil, nil, io.EOF}},
+		},
+		"cr": {
+			// CR not followed by LF is part of the line.
+			input: []string{"a\rb\n", "c\r\r\n"},
+			want:  want{lines: []string{"a\rb", "c\r"}, errs: []error{nil, nil, io.EOF}},
+		},
+		"empty-lines": {
+			input: []string{"\n\r\n\n"},
+			want:  want{lines: []string{"", "", ""}, errs: []error{nil, nil, nil, io.EOF}},
+		},
+		"no-final-lf": {
+			input: []string{"ab\n", "cd"},
+			want:  want{lines: []string{"ab", "cd"}, errs: []error{nil, nil, io.EOF}},
+		},
+		"no-final-lf-cr": {
+			input: []string{"ab\r"},
+			want:  want{lines: []string{"ab\r"}, errs: []error{nil, io.EOF}},
+		},
+		"overlong": {
+			input: []string{long + "\nok\n"},
+			want: want{
+				lines: []string{long[:limits.RunesPerLine], "ok"},
+				errs:  []error{&errors.ErrWriteOverflow, nil, io.EOF},
+			},
+		},
+		"overlong-no-final-lf": {
+			input: []string{long[:10], long[10:]},
+			want: want{
+				lines: []string{long[:limits.RunesPerLine]},
+				errs:  []error{&errors.ErrWriteOverflow, io.EOF},
+			},
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var term Terminal
+			var rec recorder
+			dev := &device{input: tt.input}
+			term.Configure(dev, []rune("> "), 80, 24, false)
+			term.EnableCooked(true)
+			term.SetKeyHandler(&rec)
+			var got want
+			for len(got.errs) <= len(tt.want.errs) {
+				err := term.ReadLine()
+				got.errs = append(got.errs, err)
+				if err == io.EOF {
+					break
+				}
+			}
+			got.lines = rec.lines
+			same := cmp.Comparer(func(a, b error) bool { return a == b })
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(want{}), same); diff != "" {
+				t.Errorf("ReadLine() mismatch (-want +got):\n%s", diff)
+			}
+			if dev.Len() != 0 {
+				t.Errorf("ReadLine() wrote %q, want nothing", dev.String())
+			}
+		})
+	}
+}
@@ -14,6 +14,7 @@ type Cursor struct {
 	disp  *display.Display
 	x, y  volatile.Register32 // X=0: left edge; Y=0: first row, current Line
 	maxY  volatile.Register32 // Greatest value of Y so far
+	top   volatile.Register32 // Screen row (1-based) of Y=0; 0 if unknown
 	ascii ascii.Uint32
 	flush bool
 	valid bool
@@ -41,11 +42,16 @@ func (c *Cursor) Reset() *Cursor {
 	return c
 }
 
-// LineFeed resets the X, Y, and MaxY coordinates and resets the I/O control
-// buffers to begin processing a new line.
+// LineFeed resets the X, Y, and MaxY coordinates and flushes the output buffer
+// to begin processing a new line. Any pending input is retained, so that keys
+// received after the end of the current line are read into the new line.
+//
+// If the screen row of the current line is known, the new line is assumed to
+// begin on the row following the current line's last row.
 func (c *Cursor) LineFeed() {
 	if c != nil && c.ctrl != nil {
-		_ = c.Reset().ctrl.Reset()
+		c.ShiftOrigin(int(c.maxY.Get()) + 1)
+		_, _ = c.Reset().ctrl.Flush()
 	}
 }
 
@@ -68,6 +74,62 @@ func (c *Cursor) X() int { return int(c.x.Get()) }
 // Y returns the Cursor's Y coordinate.
 func (c *Cursor) Y() int { return int(c.y.Get()) }
 
+// MaxY returns the greatest Y coordinate of the Cursor on the current line.
+func (c *Cursor) MaxY() int { return int(c.maxY.Get()) }
+
+// Origin returns the screen row (1-based) on which Y=0 is located.
+//
+// If the row has not been established with SetOrigin, then the current line is
+// assumed to end on the last row of the display, which is where input is read
+// once a terminal has scrolled.
+func (c *Cursor) Origin() int {
+	if top := int(c.top.Get()); top > 0 {
+		return top
+	}
+	if top := c.disp.Height() - int(c.maxY.Get()); top > 1 {
+		return top
+	}
+	return 1
+}
+
+// HasOrigin returns true if and only if the screen row on which Y=0 is located
+// was established with SetOrigin.
+func (c *Cursor) HasOrigin() bool {
+	return c.top.Get() > 0
+}
+
+// SetOrigin sets the screen row (1-based) on which Y=0 is located.
+// If row is not positive, the row is considered unknown.
+func (c *Cursor) SetOrigin(row int) {
+	if row < 0 {
+		row = 0
+	}
+	c.top.Set(uint32(row))
+}
+
+// ShiftOrigin moves the screen row on which Y=0 is located down by the given
+// number of rows, or up if rows is negative, limited to the rows of the display.
+// The screen row is unchanged if it is unknown.
+func (c *Cursor) ShiftOrigin(rows int) {
+	if top := int(c.top.Get()); top > 0 {
+		top += rows
+		if h := c.disp.Height(); top > h {
+			top = h
+		}
+		if top < 1 {
+			top = 1
+		}
+		c.top.Set(uint32(top))
+	}
+}
+
+// ScreenToLine converts the given screen column and row (1-based) to X, Y
+// coordinates relative to the current line. The returned Y is negative if the
+// given row is above the current line.
+func (c *Cursor) ScreenToLine(col, row int) (x, y int) {
+	return col - 1, row - c.Origin()
+}
+
 // Get returns the Cursor's X, Y coordinates.
 func (c *Cursor) Get() (x, y int) { return int(c.x.Get()), int(c.y.Get()) }
 
@@ -91,6 +153,10 @@ func (c *Cursor) Set(x, y int) (X, Y int) {
 	c.y.Set(uint32(y))
 	if y > int(c.maxY.Get()) {
 		c.maxY.Set(uint32(y))
+		// If the line extends beyond the last row, then the screen has scrolled.
+		if top := int(c.top.Get()); top > 0 && top+y > h {
+			c.top.Set(uint32(h - y))
+		}
 	}
 	return x, y
 }
@@ -121,6 +187,25 @@ func (c *Cursor) Update(places int) (eol bool) {
 	return places > 0 && x == 0
 }
 
+// EraseBelow appends a sequence to the output buffer that erases all glyphs from
+// the cursor's current position to the end of the display.
+//
+// Since no row of the current line remains below the cursor, MaxY is set to the
+// cursor's current Y coordinate.
+//
+// Nothing is appended for dumb terminals, which have no means of erasing glyphs
+// other than overwriting them with spaces.
+func (c *Cursor) EraseBelow() (err error) {
+	if !c.disp.Dumb() {
+		_, err = c.ctrl.Out.Write(ansi.KIB)
+	}
+	c.maxY.Set(c.y.Get())
+	if c.flush {
+		c.ctrl.Flush()
+	}
+	return
+}
+
 // Move appends key sequences to the output buffer which will move the cursor
 // in the given directions by the given number of positions, relative to the
 // cursor's current position.
@@ -128,67 +213,214 @@ func (c *Cursor) Update(places int) (eol bool) {
 // Move does not update the cursor's current X, Y coordinates. The caller is
 // responsible for updating the coordinates, which must be done every time Move
 // is called.
+//
+// Dumb terminals are only able to move the cursor left, using BS. All other
+// directions are ignored.
 func (c *Cursor) Move(up, down, left, right int) (err error) {
+	if c.disp.Dumb() {
+		c.backspace(left)
+		if c.flush {
+			c.ctrl.Flush()
+		}
+		return
+	}
 	// 1 unit up can be expressed as ^[[A or ^[A
 	// 5 units up can be expressed as ^[[5A
-	// - - - - - - - - - - - - - - - - - - - -
-	// Up
-	if up == 1 {
-		c.ctrl.Out.WriteByte(ansi.Escape)
-		c.ctrl.Out.WriteByte('[')
-		c.ctrl.Out.WriteByte('A')
-	} else if up > 1 {
-		c.ctrl.Out.WriteByte(ansi.Escape)
-		c.ctrl.Out.WriteByte('[')
-		c.ascii.Val = uint32(up)
-		c.ascii.WriteTo(c.ctrl.Out)
-		c.ctrl.Out.WriteByte('A')
+	c.csi(up, 'A')
+	c.csi(down, 'B')
+	c.csi(left, 'D')
+	c.csi(right, 'C')
+	if c.flush {
+		c.ctrl.Flush()
 	}
-	// - - - - - - - - - - - - - - - - - - - -
-	// Down
-	if down == 1 {
-		c.ctrl.Out.WriteByte(ansi.Escape)
-		c.ctrl.Out.WriteByte('[')
-		c.ctrl.Out.WriteByte('B')
-	} else if down > 1 {
-		c.ctrl.Out.WriteByte(ansi.Escape)
-		c.ctrl.Out.WriteByte('[')
-		c.ascii.Val = uint32(down)
-		c.ascii.WriteTo(c.ctrl.Out)
-		c.ctrl.Out.WriteByte('B')
+	return
+}
+
+// MoveTo appends the shortest sequence to the output buffer that moves the
+// cursor to the given X, Y coordinates, and updates the cursor's coordinates.
+//
+// The sequence is chosen from relative moves (CUU, CUD, CUF, CUB, BS), carriage
+// return followed by relative moves, and absolute positioning (CUP). Absolute
+// positioning is only used if the screen row of the current line is known (see
+// SetOrigin).
+//
+// Dumb terminals are only able to move the cursor left within the current row,
+// using BS or CR. The caller must move the cursor right by reprinting the glyphs
+// it passes over, and must not move the cursor to another row.
+func (c *Cursor) MoveTo(x, y int) (err error) {
+	xc, yc := c.Get()
+	x, y = c.Set(x, y)
+	if c.disp.Dumb() {
+		if x == 0 && xc > 1 {
+			c.ctrl.Out.WriteByte(ascii.CR)
+		} else {
+			c.backspace(xc - x)
+		}
+		if c.flush {
+			c.ctrl.Flush()
+		}
+		return
 	}
-	// - - - - - - - - - - - - - - - - - - - -
-	// Left
-	if left == 1 {
-		c.ctrl.Out.WriteByte(ansi.Escape)
-		c.ctrl.Out.WriteByte('[')
-		c.ctrl.Out.WriteByte('D')
-	} else if left > 1 {
-		c.ctrl.Out.WriteByte(ansi.Escape)
-		c.ctrl.Out.WriteByte('[')
-		c.ascii.Val = uint32(left)
-		c.ascii.WriteTo(c.ctrl.Out)
-		c.ctrl.Out.WriteByte('D')
+	var up, down int
+	if y < yc {
+		up = yc - y
+	} else {
+		down = y - yc
 	}
-	// - - - - - - - - - - - - - - - - - - - -
-	// Right
-	if right == 1 {
-		c.ctrl.Out.WriteByte(ansi.Escape)
-		c.ctrl.Out.WriteByte('[')
-		c.ctrl.Out.WriteByte('C')
-	} else if right > 1 {
+	vert := csiLen(up) + csiLen(down)
+	// Relative moves only
+	cost, mode := vert, moveRelative
+	if x < xc {
+		cost += leftLen(xc - x)
+	} else {
+		cost += csiLen(x - xc)
+	}
+	// Carriage return, then relative moves
+	if n := vert + 1 + csiLen(x); x < xc && n < cost {
+		cost, mode = n, moveReturn
+	}
+	// Absolute positioning
+	if top := int(c.top.Get()); top > 0 {
+		if n := cupLen(top+y, x+1); n < cost {
+			cost, mode = n, moveAbsolute
+		}
+	}
+	switch mode {
+	case moveRelative:
+		c.csi(up, 'A')
+		c.csi(down, 'B')
+		if x < xc {
+			if n := xc - x; n < csiLen(n) {
+				c.backspace(n)
+			} else {
+				c.csi(n, 'D')
+			}
+		} else {
+			c.csi(x-xc, 'C')
+		}
+	case moveReturn:
+		c.ctrl.Out.WriteByte(ascii.CR)
+		c.csi(up, 'A')
+		c.csi(down, 'B')
+		c.csi(x, 'C')
+	case moveAbsolute:
 		c.ctrl.Out.WriteByte(ansi.Escape)
 		c.ctrl.Out.WriteByte('[')
-		c.ascii.Val = uint32(right)
-		c.ascii.WriteTo(c.ctrl.Out)
-		c.ctrl.Out.WriteByte('C')
+		if row, col := int(c.top.Get())+y, x+1; row > 1 || col > 1 {
+			c.ascii.Val = uint32(row)
+			c.ascii.WriteTo(c.ctrl.Out)
+			if col > 1 {
+				c.ctrl.Out.WriteByte(';')
+				c.ascii.Val = uint32(col)
+				c.ascii.WriteTo(c.ctrl.Out)
+			}
+		}
+		c.ctrl.Out.WriteByte('H')
+	}
+	if c.flush {
+		c.ctrl.Flush()
 	}
+	return
+}
+
+// InsertChars appends a sequence (ICH) to the output buffer that inserts n blank
+// characters at the cursor's current position, shifting the characters right of
+// the cursor to the right. The cursor does not move.
+//
+// Not all terminals support ICH; see display.CapInsertChar.
+func (c *Cursor) InsertChars(n int) (err error) {
+	c.csi(n, '@')
+	if c.flush {
+		c.ctrl.Flush()
+	}
+	return
+}
+
+// DeleteChars appends a sequence (DCH) to the output buffer that deletes n
+// characters at the cursor's current position, shifting the characters right of
+// the cursor to the left. The cursor does not move.
+//
+// Not all terminals support DCH; see display.CapDeleteChar.
+func (c *Cursor) DeleteChars(n int) (err error) {
+	c.csi(n, 'P')
 	if c.flush {
 		c.ctrl.Flush()
 	}
 	return
 }
 
+// Methods of moving the cursor compared by MoveTo.
+const (
+	moveRelative = iota
+	moveReturn
+	moveAbsolute
+)
+
+// backspace appends n BS to the output buffer, which move the cursor n places to
+// the left. Nothing is appended if n < 1.
+func (c *Cursor) backspace(n int) {
+	for ; n > 0; n-- {
+		c.ctrl.Out.WriteByte(ascii.BS)
+	}
+}
+
+// csi appends a control sequence with the given numeric parameter n and final
+// byte to the output buffer. The parameter is omitted if n = 1, and nothing is
+// appended if n < 1.
+func (c *Cursor) csi(n int, final byte) {
+	if n < 1 {
+		return
+	}
+	c.ctrl.Out.WriteByte(ansi.Escape)
+	c.ctrl.Out.WriteByte('[')
+	if n > 1 {
+		c.ascii.Val = uint32(n)
+		c.ascii.WriteTo(c.ctrl.Out)
+	}
+	c.ctrl.Out.WriteByte(final)
+}
+
+// csiLen returns the number of bytes appended by csi for parameter n.
+func csiLen(n int) int {
+	switch {
+	case n < 1:
+		return 0
+	case n == 1:
+		return 3
+	}
+	return 3 + digits(n)
+}
+
+// leftLen returns the number of bytes required to move the cursor n places to
+// the left, using either BS or CUB.
+func leftLen(n int) int {
+	if m := csiLen(n); m < n {
+		return m
+	}
+	return n
+}
+
+// cupLen returns the number of bytes in a CUP sequence to the given screen row
+// and column (1-based).
+func cupLen(row, col int) int {
+	n := 3
+	if row > 1 || col > 1 {
+		n += digits(row)
+	}
+	if col > 1 {
+		n += 1 + digits(col)
+	}
+	return n
+}
+
+// digits returns the number of decimal digits in n.
+func digits(n int) (d int) {
+	for d = 1; n >= 10; n /= 10 {
+		d++
+	}
+	return
+}
+
 func (c *Cursor) WriteBuf(buf []byte) {
 	for _, b := range buf {
 		c.ascii.Val = uint32(b)
@@ -0,0 +1,61 @@
+package display
+
+import (
+	"strings"
+
+	"github.com/ardnew/embedit/seq/ansi"
+)
+
+// Discover returns the optional control functions and color depth supported by
+// the terminal identified by the given terminal type, e.g., the TERM environment
+// variable or the telnet TERMINAL-TYPE option, and the COLORTERM environment
+// variable, which may be empty.
+//
+// Unrecognized terminal types are assumed to support 16 colors and no optional
+// control functions. An empty terminal type or "dumb" supports neither, and
+// should also be edited without any control sequences (see SetDumb).
+func Discover(term, colorterm string) (caps Capability, depth ansi.Depth) {
+	switch {
+	case term == "" || term == "dumb":
+		return 0, ansi.DepthNone
+	case strings.HasPrefix(term, "vt52"):
+		return 0, ansi.DepthNone
+	case term == "vt100" || strings.HasPrefix(term, "vt100-"):
+		return 0, ansi.DepthMono
+	case strings.HasPrefix(term, "vt1"):
+		// VT102 supports DCH, but not ICH, which was introduced with VT220.
+		return CapDeleteChar, ansi.DepthMono
+	case len(term) > 2 && term[:2] == "vt" && '2' <= term[2] && term[2] <= '9':
+		return CapInsertChar | CapDeleteChar, ansi.DepthMono
+	}
+	caps = CapInsertChar | CapDeleteChar
+	switch {
+	case colorterm == "truecolor" || colorterm == "24bit",
+		strings.Contains(term, "truecolor"),
+		strings.Contains(term, "24bit"),
+		strings.Contains(term, "direct"):
+		depth = ansi.DepthTrue
+	case strings.Contains(term, "256"):
+		depth = ansi.Depth256
+	case strings.Contains(term, "mono"),
+		strings.HasSuffix(term, "-m"):
+		depth = ansi.DepthMono
+	case hasPrefix(term, "xterm", "screen", "tmux", "rxvt", "linux", "ansi",
+		"putty", "konsole", "gnome", "alacritty", "kitty", "foot", "vte", "st-",
+		"cygwin") || term == "st":
+		depth = ansi.Depth16
+	default:
+		caps, depth = 0, ansi.Depth16
+	}
+	return
+}
+
+// hasPrefix returns true if and only if s begins with any of the given prefixes.
+func hasPrefix(s string, prefix ...string) bool {
+	for _, p := range prefix {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
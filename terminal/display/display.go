@@ -2,18 +2,57 @@ package display
 
 import (
 	"github.com/ardnew/embedit/config/defaults"
+	"github.com/ardnew/embedit/config/limits"
+	"github.com/ardnew/embedit/seq/ansi"
+	"github.com/ardnew/embedit/seq/ascii"
 	"github.com/ardnew/embedit/seq/utf8"
 	"github.com/ardnew/embedit/volatile"
 )
 
+// Capability is a bitmask of optional control functions supported by a
+// terminal, which may be used to reduce the number of bytes written.
+type Capability uint8
+
+// Constant bits of enumerated type Capability.
+const (
+	CapInsertChar Capability = 1 << iota // ICH — Insert blank characters
+	CapDeleteChar                        // DCH — Delete characters
+)
+
+// Prompter is implemented by applications that generate the user input prompt
+// each time it is drawn, e.g., to show the current mode, the history index, or
+// the status of the last command.
+type Prompter interface {
+	// Prompt copies the runes of the prompt into p, and returns the number of
+	// runes copied. Runes beyond the length of p are truncated.
+	Prompt(p []rune) (n int)
+}
+
 // Display defines a terminal display's viewport.
 type Display struct {
+	model          Model
+	sgr            ansi.SGR
+	pen            ansi.Style // Style of glyphs drawn next
+	highlighter    Highlighter
+	spans          []Span
+	span           int // Number of spans filled by highlighter
+	suggester      Suggester
+	suggestion     [limits.RunesPerLine]rune
+	suggested      int // Number of runes in suggestion
 	promptIterable utf8.Iterable
-	prompt         []rune
+	prompt         []rune // Runes of the prompt in promptBuffer
+	promptBuffer   [limits.RunesPerPrompt]rune
+	prompter       Prompter
 	promptEnabled  bool
+	rightIterable  utf8.Iterable
+	right          []rune // Runes of the right prompt in rightBuffer
+	rightBuffer    [limits.RunesPerPrompt]rune
 	width          volatile.Register32
 	height         volatile.Register32
 	echo           volatile.Register8
+	scroll         volatile.Register8
+	dumb           volatile.Register8
+	caps           volatile.Register8
 	valid          bool
 }
 
@@ -94,23 +133,117 @@ func (d *Display) SetEcho(echo bool) {
 	}
 }
 
-// Prompt returns the user input prompt.
-func (d *Display) Prompt() []rune {
-	if d == nil || !d.valid || !d.promptEnabled {
+// HorizontalScroll returns true if and only if the user input line is kept on a
+// single row and scrolled horizontally to keep the cursor visible, instead of
+// wrapping onto multiple rows.
+//
+// Dumb terminals are always scrolled horizontally, since moving the cursor to
+// another row requires control sequences.
+func (d *Display) HorizontalScroll() bool {
+	return d != nil && (d.scroll.Get() != 0 || d.dumb.Get() != 0)
+}
+
+// SetHorizontalScroll sets horizontal scrolling true if and only if the user
+// input line is kept on a single row.
+func (d *Display) SetHorizontalScroll(scroll bool) {
+	if d != nil {
+		was := d.HorizontalScroll()
+		if scroll {
+			d.scroll.Set(1)
+		} else {
+			d.scroll.Set(0)
+		}
+		if d.HorizontalScroll() != was {
+			// The cells of the model are laid out differently.
+			d.model.Reset()
+		}
+	}
+}
+
+// Dumb returns true if and only if the terminal does not recognize any control
+// sequences, e.g., a printing terminal, a serial capture log, or TERM=dumb.
+//
+// The line is then edited using only BS, CR, spaces, and reprinting its text.
+func (d *Display) Dumb() bool {
+	return d != nil && d.dumb.Get() != 0
+}
+
+// SetDumb sets dumb true if and only if the terminal does not recognize any
+// control sequences.
+func (d *Display) SetDumb(dumb bool) {
+	if d != nil {
+		was := d.HorizontalScroll()
+		if dumb {
+			d.dumb.Set(1)
+		} else {
+			d.dumb.Set(0)
+		}
+		if d.HorizontalScroll() != was {
+			// The cells of the model are laid out differently.
+			d.model.Reset()
+		}
+	}
+}
+
+// Capabilities returns the optional control functions supported by the terminal.
+func (d *Display) Capabilities() Capability {
+	if d == nil {
+		return 0
+	}
+	return Capability(d.caps.Get())
+}
+
+// SetCapabilities sets the optional control functions supported by the
+// terminal. By default, no optional control functions are used.
+func (d *Display) SetCapabilities(caps Capability) {
+	if d != nil {
+		d.caps.Set(uint8(caps))
+	}
+}
+
+// Depth returns the number of colors the terminal is able to display.
+func (d *Display) Depth() ansi.Depth {
+	if d == nil || !d.valid {
+		return ansi.DepthNone
+	}
+	return d.sgr.Depth()
+}
+
+// SetDepth sets the number of colors the terminal is able to display. Styles
+// written with SGR are downgraded to depth.
+func (d *Display) SetDepth(depth ansi.Depth) {
+	if d != nil {
+		d.sgr.SetDepth(depth)
+	}
+}
+
+// SGR returns the writer of SGR sequences, which downgrades styles to the color
+// depth of the terminal.
+func (d *Display) SGR() *ansi.SGR {
+	if d == nil || !d.valid {
 		return nil
 	}
-	if d.prompt == nil {
-		return defaults.Prompt
+	return &d.sgr
+}
+
+// Model returns the model of the cells drawn in the rows of the display owned
+// by the current line of input.
+func (d *Display) Model() *Model {
+	if d == nil || !d.valid {
+		return nil
 	}
-	return d.prompt
+	return &d.model
 }
 
-// promptIterator returns the user input prompt as utf8.RuneIterator.
-func (d *Display) promptIterator() utf8.Iterator {
-	if d.prompt == nil {
-		return (*utf8.IterableRune)(&defaults.Prompt)
+// Prompt returns the user input prompt.
+//
+// The returned slice refers to the storage of d, and is only valid until the
+// prompt is changed.
+func (d *Display) Prompt() []rune {
+	if d == nil || !d.valid || !d.promptEnabled {
+		return nil
 	}
-	return (*utf8.IterableRune)(&d.prompt)
+	return d.prompt
 }
 
 // promptIterator returns the user input prompt as utf8.RuneIterator.
@@ -130,18 +263,160 @@ func (d *Display) GlyphCountInPrompt() (count int) {
 	return d.promptIterable.Reset().GlyphCount()
 }
 
-// SetPrompt sets the user input prompt.
+// PromptWidth returns the number of columns occupied by the runes in the last
+// row of the user input prompt that are not part of an escape sequence.
+//
+// A prompt containing newlines occupies multiple rows, and only its last row
+// precedes the user input line.
+func (d *Display) PromptWidth() (width int) {
+	if d == nil || !d.valid || !d.promptEnabled {
+		return 0
+	}
+	lo, _ := d.promptRows()
+	if s := d.promptIterable.Slice(lo, -1); s != nil {
+		width = s.Width()
+	}
+	return
+}
+
+// PromptRows returns the number of display rows occupied by the rows of the
+// user input prompt preceding its last row, which are drawn above the user
+// input line.
+func (d *Display) PromptRows() (rows int) {
+	if d == nil || !d.valid || !d.promptEnabled {
+		return 0
+	}
+	_, rows = d.promptRows()
+	return
+}
+
+// promptRows returns the index of the first rune in the last row of the user
+// input prompt, and the number of display rows occupied by the preceding rows.
+func (d *Display) promptRows() (lo, rows int) {
+	s := d.promptIterable.Reset()
+	if s == nil {
+		return
+	}
+	w := d.Width()
+	for i, n := 0, int(s.RuneTail()-s.RuneHead()); i < n; i++ {
+		if rune(*s.RuneAt(int(s.RuneHead()) + i)) != ascii.LF {
+			continue
+		}
+		// A row wider than the display wraps onto the rows following it.
+		rows++
+		if t := d.promptIterable.Slice(lo, i); t != nil && w > 0 {
+			rows += (t.Width() - 1) / w
+		}
+		lo = i + 1
+	}
+	return
+}
+
+// SetPrompt sets the user input prompt to a copy of the given prompt, which is
+// truncated to limits.RunesPerPrompt runes. If prompt is nil, the default prompt
+// is used.
+//
+// The prompt is replaced each time it is drawn if a Prompter was set.
 func (d *Display) SetPrompt(prompt []rune) {
 	if d != nil {
 		if prompt == nil {
-			for i := range d.prompt {
-				d.prompt[i] = 0
-			}
-		} else {
-			d.prompt = prompt
+			prompt = defaults.Prompt
+		}
+		d.prompt = d.promptBuffer[:copy(d.promptBuffer[:], prompt)]
+		d.promptIterable.Iterator = (*utf8.IterableRune)(&d.prompt)
+	}
+}
+
+// SetPrompter sets the Prompter that generates the user input prompt each time
+// it is drawn. If p is nil, the prompt last generated or set with SetPrompt is
+// retained.
+func (d *Display) SetPrompter(p Prompter) {
+	if d != nil {
+		d.prompter = p
+	}
+}
+
+// UpdatePrompt replaces the user input prompt with one generated by the
+// Prompter, if it was set. UpdatePrompt is called each time before the prompt
+// is drawn.
+func (d *Display) UpdatePrompt() {
+	if d == nil || d.prompter == nil {
+		return
+	}
+	n := d.prompter.Prompt(d.promptBuffer[:])
+	if n < 0 {
+		n = 0
+	} else if n > len(d.promptBuffer) {
+		n = len(d.promptBuffer)
+	}
+	d.prompt = d.promptBuffer[:n]
+	d.promptIterable.Iterator = (*utf8.IterableRune)(&d.prompt)
+}
+
+// PromptEnabled returns true if and only if the user input prompt is enabled,
+// which is the case while a line is being read.
+func (d *Display) PromptEnabled() bool {
+	return d != nil && d.valid && d.promptEnabled
+}
+
+// RightPrompt returns the prompt drawn at the right edge of the first row of the
+// user input line, or nil if there is none.
+func (d *Display) RightPrompt() []rune {
+	if d == nil || !d.valid || !d.promptEnabled {
+		return nil
+	}
+	return d.right
+}
+
+// RightPromptIterable returns the right prompt as utf8.Iterable, or nil if there
+// is none.
+func (d *Display) RightPromptIterable() *utf8.Iterable {
+	if d == nil || !d.valid || !d.promptEnabled || len(d.right) == 0 {
+		return nil
+	}
+	return &d.rightIterable
+}
+
+// RightPromptWidth returns the number of columns occupied by the runes in the
+// right prompt that are not part of an escape sequence.
+func (d *Display) RightPromptWidth() int {
+	if s := d.RightPromptIterable(); s != nil {
+		return s.Reset().Width()
+	}
+	return 0
+}
+
+// SetRightPrompt sets the prompt drawn at the right edge of the first row of the
+// user input line to a copy of the given prompt, which must not contain newlines
+// and is truncated to limits.RunesPerPrompt runes. The right prompt is hidden
+// while the user input line extends into the columns it occupies.
+//
+// If prompt is empty, no right prompt is drawn.
+func (d *Display) SetRightPrompt(prompt []rune) {
+	if d == nil || equalRunes(d.right, prompt) {
+		return
+	}
+	d.right = d.rightBuffer[:copy(d.rightBuffer[:], prompt)]
+	d.rightIterable.Iterator = (*utf8.IterableRune)(&d.right)
+	// A right prompt drawn in the same columns is drawn over with the new one.
+	// Otherwise, it is erased when the line is next drawn.
+	if col := d.model.RightPrompt(); col >= 0 &&
+		col == d.Width()-1-d.rightIterable.Reset().Width() {
+		d.model.SetRightPrompt(-1)
+	}
+}
+
+// equalRunes returns true if and only if a and b contain the same runes.
+func equalRunes(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
 		}
-		d.promptIterable.Iterator = d.promptIterator()
 	}
+	return true
 }
 
 // EnablePrompt enables or disables the user input prompt.
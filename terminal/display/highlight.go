@@ -0,0 +1,90 @@
+package display
+
+import (
+	"github.com/ardnew/embedit/seq/ansi"
+	"github.com/ardnew/embedit/seq/utf8"
+	"github.com/ardnew/embedit/terminal/wire"
+)
+
+// Span is a range of runes in a line of input drawn with the same Style.
+type Span struct {
+	Lo, Hi int // Logical positions of the first rune and the rune following it
+	Style  ansi.Style
+}
+
+// Highlighter is implemented by applications that style the text of a line of
+// input as it is typed, e.g., to color commands, arguments, and invalid tokens.
+type Highlighter interface {
+	// Highlight fills spans with the styles of the runes in text, and returns
+	// the number of spans filled. Spans must be ordered by position and must not
+	// overlap. Runes not in any span are drawn with the default style.
+	//
+	// The text must not be modified.
+	Highlight(text *utf8.Iterable, spans []Span) (n int)
+}
+
+// SetHighlighter sets the Highlighter that styles the text of the line each
+// time it is drawn, and the array of spans it fills. If h is nil or spans is
+// empty, the line is drawn with the default style.
+func (d *Display) SetHighlighter(h Highlighter, spans []Span) {
+	if d != nil {
+		d.highlighter = h
+		d.spans = spans
+		d.span = 0
+	}
+}
+
+// Highlight calls the Highlighter, if any, with the given text of the line, and
+// retains the spans it filled for StyleAt.
+func (d *Display) Highlight(text *utf8.Iterable) {
+	if d == nil {
+		return
+	}
+	d.span = 0
+	if d.highlighter == nil || len(d.spans) == 0 || text == nil {
+		return
+	}
+	n := d.highlighter.Highlight(text, d.spans)
+	if n < 0 {
+		n = 0
+	} else if n > len(d.spans) {
+		n = len(d.spans)
+	}
+	d.span = n
+}
+
+// StyleAt returns the style of the rune at the given logical position of the
+// line, according to the spans last filled by the Highlighter.
+func (d *Display) StyleAt(position int) ansi.Style {
+	if d == nil {
+		return ansi.Style{}
+	}
+	for _, s := range d.spans[:d.span] {
+		if position < s.Lo {
+			break
+		}
+		if position < s.Hi {
+			return s.Style
+		}
+	}
+	return ansi.Style{}
+}
+
+// ResetStyle records that glyphs are drawn with the default style, e.g., after
+// the display was cleared or at the start of a new sequence of output.
+func (d *Display) ResetStyle() {
+	if d != nil {
+		d.pen = ansi.Style{}
+	}
+}
+
+// SetStyle appends the sequence to w that sets the style of glyphs drawn after
+// it to s, unless s is already set. The style is downgraded to the color depth
+// of the terminal. Nothing is appended to w for dumb terminals.
+func (d *Display) SetStyle(w wire.Writer, s ansi.Style) (err error) {
+	if d == nil || s == d.pen || d.Dumb() {
+		return
+	}
+	d.pen = s
+	return d.sgr.Write(w, s)
+}
@@ -0,0 +1,117 @@
+package display
+
+import (
+	"github.com/ardnew/embedit/config/limits"
+	"github.com/ardnew/embedit/volatile"
+)
+
+// Cell identifies the glyph drawn in a single column of the display.
+//
+// Two cells are equal if and only if they display the same glyph. A glyph
+// consisting of a rune and any zero-width runes combined with it (e.g.,
+// combining marks) is identified by a hash of all of its runes.
+type Cell uint32
+
+// Constant values of type Cell with special meaning.
+const (
+	CellWide    Cell = 0         // Right column of a glyph occupying two columns
+	CellBlank   Cell = ' '       // Column containing no glyph
+	CellUnknown Cell = 1<<32 - 1 // Column whose content is unknown
+)
+
+// MakeCell returns the Cell identifying the glyph of a single rune r.
+func MakeCell(r rune) Cell {
+	if r == 0 {
+		return CellBlank
+	}
+	return Cell(r)
+}
+
+// Combine returns the Cell identifying the glyph of c combined with the
+// zero-width rune r.
+func (c Cell) Combine(r rune) Cell {
+	if c = (c*31 + Cell(r)) | 1<<31; c == CellUnknown {
+		c--
+	}
+	return c
+}
+
+// Model is a fixed-size model of the cells drawn in the rows of the display that
+// are owned by a line of input, starting from the cell that follows the prompt.
+//
+// Cells are indexed by their column relative to the end of the prompt, counted
+// across rows; e.g., on a display with 80 columns and a prompt of width 2, cell
+// 78 is the first column in the second row of the line.
+//
+// Model also records the column at which the right prompt is drawn in the first
+// row, if any.
+type Model struct {
+	cell  [limits.CellsPerLine]Cell
+	size  volatile.Register32
+	right volatile.Register32 // Column of the right prompt plus 1, or 0 if not drawn
+}
+
+// Reset marks all cells in m unknown, and the right prompt not drawn.
+func (m *Model) Reset() {
+	if m != nil {
+		m.size.Set(0)
+		m.right.Set(0)
+	}
+}
+
+// RightPrompt returns the column at which the right prompt is drawn, or -1 if it
+// is not drawn.
+func (m *Model) RightPrompt() int {
+	if m == nil {
+		return -1
+	}
+	return int(m.right.Get()) - 1
+}
+
+// SetRightPrompt sets the column at which the right prompt is drawn. If col is
+// negative, the right prompt is not drawn.
+func (m *Model) SetRightPrompt(col int) {
+	if m != nil {
+		if col < 0 {
+			col = -1
+		}
+		m.right.Set(uint32(col + 1))
+	}
+}
+
+// Len returns the number of cells in m whose content is known.
+func (m *Model) Len() int {
+	if m == nil {
+		return 0
+	}
+	return int(m.size.Get())
+}
+
+// SetLen sets the number of cells in m whose content is known. All cells
+// following the first n cells are marked unknown.
+func (m *Model) SetLen(n int) {
+	if m != nil {
+		if n < 0 {
+			n = 0
+		} else if n > limits.CellsPerLine {
+			n = limits.CellsPerLine
+		}
+		m.size.Set(uint32(n))
+	}
+}
+
+// At returns the cell at index i in m, or CellUnknown if i is out of range.
+func (m *Model) At(i int) Cell {
+	if m == nil || i < 0 || i >= int(m.size.Get()) {
+		return CellUnknown
+	}
+	return m.cell[i]
+}
+
+// Set sets the cell at index i in m to c.
+// Cells at indices not less than Len remain unknown until SetLen is called.
+func (m *Model) Set(i int, c Cell) {
+	if m != nil && 0 <= i && i < limits.CellsPerLine {
+		m.cell[i] = c
+	}
+}
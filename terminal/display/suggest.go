@@ -0,0 +1,85 @@
+package display
+
+import (
+	"github.com/ardnew/embedit/config/limits"
+	"github.com/ardnew/embedit/seq/ansi"
+	"github.com/ardnew/embedit/seq/utf8"
+)
+
+// Suggester is implemented by sources of suggestions that complete the text of a
+// line of input, e.g., the line history.
+type Suggester interface {
+	// Suggest copies the runes that follow text in the suggested completion into
+	// p, and returns the number of runes copied. If there is no suggestion, or
+	// the suggestion does not fit in p, Suggest returns 0.
+	//
+	// The text must not be modified.
+	Suggest(text *utf8.Iterable, p []rune) (n int)
+}
+
+// SuggestionStyle is the style of a suggestion drawn following the text of a
+// line of input.
+var SuggestionStyle = ansi.Style{}.Dim()
+
+// SetSuggester sets the Suggester that completes the text of the line each time
+// it is changed. If s is nil, no suggestions are made.
+func (d *Display) SetSuggester(s Suggester) {
+	if d != nil {
+		d.suggester = s
+		d.suggested = 0
+	}
+}
+
+// Suggester returns the Suggester that completes the text of the line, or nil
+// if there is none.
+func (d *Display) Suggester() Suggester {
+	if d == nil {
+		return nil
+	}
+	return d.suggester
+}
+
+// Suggest calls the Suggester, if any, with the given text of the line, which
+// contains count runes, and retains the suggestion for Suggestion.
+//
+// No suggestion is made for an empty line, or if the terminal cannot display
+// styles to distinguish the suggestion from the text.
+func (d *Display) Suggest(text *utf8.Iterable, count int) {
+	if d == nil {
+		return
+	}
+	d.suggested = 0
+	if d.suggester == nil || count <= 0 || count >= limits.RunesPerLine ||
+		d.sgr.Depth() >= ansi.DepthNone || text == nil {
+		return
+	}
+	// The text and suggestion together must fit in a line.
+	p := d.suggestion[:limits.RunesPerLine-count]
+	if n := d.suggester.Suggest(text, p); 0 < n && n <= len(p) {
+		d.suggested = n
+	}
+}
+
+// Suggestion returns the runes of the suggestion following the text of the
+// line, or nil if there is none.
+//
+// The returned slice refers to the storage of d, and is only valid until the
+// next suggestion is made.
+func (d *Display) Suggestion() []rune {
+	if d == nil || d.suggested == 0 {
+		return nil
+	}
+	return d.suggestion[:d.suggested]
+}
+
+// SuggestionAt returns the rune at index i of the suggestion.
+func (d *Display) SuggestionAt(i int) *utf8.Rune {
+	return (*utf8.Rune)(&d.suggestion[i])
+}
+
+// ClearSuggestion discards the suggestion following the text of the line.
+func (d *Display) ClearSuggestion() {
+	if d != nil {
+		d.suggested = 0
+	}
+}
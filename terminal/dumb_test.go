@@ -0,0 +1,65 @@
+package terminal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/ardnew/embedit/seq/ansi"
+)
+
+func TestTerminal_ReadLineDumb(t *testing.T) {
+	t.Parallel()
+	type want struct {
+		line   string
+		output string
+	}
+	for name, tt := range map[string]struct {
+		input []string
+		want  want
+	}{
+		"type": {
+			input: []string{"hello\r"},
+			want:  want{line: "hello", output: "> hello\r\r\n"},
+		},
+		"edit": {
+			// Arrow keys received from the terminal move the cursor without writing
+			// any escape sequences.
+			input: []string{"helo", "\x1b[D", "l", "\x1b[C!", "\x7f\r"},
+			want:  want{line: "hello", output: "> helo\blo\bo!\b \b\r\r\n"},
+		},
+		"clear-screen": {
+			// Ctrl+L begins a new row instead of erasing the display.
+			input: []string{"ab\x0cc\r"},
+			want:  want{line: "abc", output: "> ab\r\r\n> abc\r\r\n"},
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var term Terminal
+			var rec recorder
+			dev := &device{input: tt.input}
+			term.Configure(dev, []rune("> "), 20, 24, false)
+			term.EnableDumb(true)
+			term.EnableMouse(true)
+			term.EnableResync(true)
+			term.SetDepth(ansi.DepthTrue)
+			term.SetKeyHandler(&rec)
+			if err := term.ReadLine(); err != nil {
+				t.Fatalf("ReadLine() error = %v", err)
+			}
+			got := want{output: dev.String()}
+			if len(rec.lines) > 0 {
+				got.line = rec.lines[0]
+			}
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("ReadLine() mismatch (-want +got):\n%s", diff)
+			}
+			if strings.Contains(got.output, "\x1b") {
+				t.Errorf("ReadLine() output contains ESC: %q", got.output)
+			}
+		})
+	}
+}
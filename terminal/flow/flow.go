@@ -0,0 +1,26 @@
+package flow
+
+// State represents the state of software (XON/XOFF) flow control.
+type State byte
+
+// Constant values of enumerated type State.
+const (
+	Resumed State = iota // XON received, or no XOFF received
+	Paused               // XOFF received
+)
+
+// IsPaused returns true if and only if s is Paused.
+func (s State) IsPaused() bool {
+	return s == Paused
+}
+
+// Policy defines the behavior when output is written while Paused, and the
+// output buffer does not have enough free space to hold it.
+type Policy byte
+
+// Constant values of enumerated type Policy.
+const (
+	Discard Policy = iota // Discard output that does not fit in the buffer
+	Block                 // Read input until XON is received or time out, then flush
+	Resume                // Flush anyway as if XON had been received
+)
@@ -0,0 +1,108 @@
+package terminal
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/ardnew/embedit/errors"
+	"github.com/ardnew/embedit/terminal/flow"
+)
+
+func TestTerminal_FlowControl(t *testing.T) {
+	t.Parallel()
+	const (
+		xon  = "\x11"
+		xoff = "\x13"
+	)
+	type want struct {
+		written string // Output written to the device
+		pending int    // Bytes retained in the output buffer, or -1 if full
+		input   int    // Bytes of input retained
+		paused  bool
+		err     error
+	}
+	for name, tt := range map[string]struct {
+		input  []string // Input received while the output buffer is full
+		policy flow.Policy
+		fill   bool // Fill the output buffer before writing
+		want   want
+	}{
+		"paused": {
+			want: want{pending: 2, paused: true},
+		},
+		"resumed": {
+			input: []string{xon},
+			want:  want{written: "yz"},
+		},
+		"resumed-keys": {
+			input: []string{"a" + xoff + "b" + xon + "c"},
+			want:  want{written: "yz", input: 3},
+		},
+		"discard": {
+			policy: flow.Discard,
+			fill:   true,
+			want:   want{pending: -1, paused: true, err: &errors.ErrWriteOverflow},
+		},
+		"resume": {
+			policy: flow.Resume,
+			fill:   true,
+			want:   want{written: "fillyz"},
+		},
+		"block": {
+			input:  []string{"ab", xon},
+			policy: flow.Block,
+			fill:   true,
+			want:   want{written: "fillyz", input: 2},
+		},
+		"block-timeout": {
+			input:  []string{"ab", xoff},
+			policy: flow.Block,
+			fill:   true,
+			want:   want{pending: -1, paused: true, input: 2, err: &errors.ErrTimeout},
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var term Terminal
+			dev := &deadlineDevice{device{block: true, input: []string{xoff}}}
+			term.Configure(dev, []rune("> "), 80, 24, false)
+			term.EnableFlowControl(true, tt.policy)
+			term.SetFlowTimeout(20 * time.Millisecond)
+			_, _ = term.Swell()
+			fill := ""
+			if tt.fill {
+				fill = strings.Repeat("x", term.out.Cap())
+				if _, err := term.output.Write([]byte(fill)); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+			}
+			dev.input = tt.input
+			if !tt.fill {
+				// Without a full buffer, input is only read while reading a line.
+				for len(dev.input) > 0 {
+					_, _ = term.Swell()
+				}
+			}
+			var got want
+			_, got.err = term.output.Write([]byte("yz"))
+			_, _ = term.Flush()
+			got.written = dev.String()
+			if fill != "" {
+				got.written = strings.Replace(got.written, fill, "fill", 1)
+			}
+			got.pending, got.input = term.out.Len(), term.in.Len()
+			if got.pending == term.out.Cap() {
+				got.pending = -1
+			}
+			got.paused = term.flow.IsPaused()
+			same := cmp.Comparer(func(a, b error) bool { return a == b })
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(want{}), same); diff != "" {
+				t.Errorf("Write() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
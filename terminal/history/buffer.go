@@ -5,6 +5,7 @@ package history
 
 import (
 	"github.com/ardnew/embedit/config/limits"
+	"github.com/ardnew/embedit/seq/utf8"
 	"github.com/ardnew/embedit/terminal/line"
 )
 
@@ -48,7 +49,7 @@ func (h *History) Add() {
 	}
 	// Reset our History pointer
 	h.indx.Set(0)
-	// Reset the cursor, data, and I/O buffers.
+	// Reset the cursor and data, and flush the output buffer.
 	h.pend.LineFeed()
 }
 
@@ -56,7 +57,6 @@ func (h *History) Back() {
 	indx, size := h.indx.Get(), h.size.Get()
 	if indx < size-1 {
 		*h.get(int(indx)) = h.pend
-		h.pend.Set(nil)
 		indx++
 		h.pend = *h.get(int(indx))
 
@@ -70,7 +70,6 @@ func (h *History) Forward() {
 	indx := h.indx.Get()
 	if indx > 0 {
 		*h.get(int(indx)) = h.pend
-		h.pend.Set(nil)
 		indx--
 		h.pend = *h.get(int(indx))
 
@@ -79,3 +78,40 @@ func (h *History) Forward() {
 		h.indx.Set(indx)
 	}
 }
+
+// Suggest copies the runes following text in the most recent Line in History
+// that begins with text into p, and returns the number of runes copied.
+// Returns 0 if no Line begins with text, or if the remaining runes of the most
+// recent such Line do not fit in p.
+//
+// Suggest implements display.Suggester.
+func (h *History) Suggest(text *utf8.Iterable, p []rune) (n int) {
+	if h == nil || !h.valid || text == nil {
+		return 0
+	}
+	count := text.Count()
+	for i := 1; i < int(h.size.Get()); i++ {
+		ln := h.get(i)
+		size := ln.RuneCount()
+		if size <= count {
+			continue
+		}
+		head := int(ln.RuneHead())
+		match := true
+		for k, s := 0, text.Reset(); match && k < count; k++ {
+			match = *s.Next() == *ln.RuneAt(head + k)
+		}
+		if !match {
+			continue
+		}
+		if size-count > len(p) {
+			return 0
+		}
+		for k := count; k < size; k++ {
+			p[n] = rune(*ln.RuneAt(head + k))
+			n++
+		}
+		return
+	}
+	return 0
+}
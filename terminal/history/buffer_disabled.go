@@ -4,6 +4,7 @@
 package history
 
 import (
+	"github.com/ardnew/embedit/seq/utf8"
 	"github.com/ardnew/embedit/terminal/line"
 )
 
@@ -26,7 +27,7 @@ func (h *History) Add() {
 	if h == nil || !h.valid {
 		return
 	}
-	// Reset the cursor, data, and I/O buffers.
+	// Reset the cursor and data, and flush the output buffer.
 	h.pend.LineFeed()
 }
 
@@ -35,3 +36,11 @@ func (h *History) Back() {
 
 func (h *History) Forward() {
 }
+
+// Suggest copies the runes following text in the most recent Line in History
+// that begins with text into p, and returns the number of runes copied.
+//
+// Without build tag "history", no Lines are stored, and Suggest returns 0.
+func (h *History) Suggest(text *utf8.Iterable, p []rune) (n int) {
+	return 0
+}
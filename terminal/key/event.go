@@ -0,0 +1,139 @@
+package key
+
+import "github.com/ardnew/embedit/config/limits"
+
+// Modifier is a bitmask of the modifier keys held when a key was pressed.
+type Modifier byte
+
+// ModNone indicates no modifier keys were held.
+const ModNone Modifier = 0
+
+// Constant bits of enumerated type Modifier.
+//
+// The bit order matches the xterm modifier parameter encoding, in which the
+// parameter value is 1 plus the bitwise OR of all held modifiers.
+const (
+	ModShift Modifier = 1 << iota
+	ModAlt
+	ModCtrl
+	ModMeta
+)
+
+// ModifierParam returns the Modifier encoded by an xterm modifier parameter p,
+// e.g., the 5 in ESC[1;5C (Ctrl+Right).
+// Returns ModNone if p is not a valid modifier parameter.
+func ModifierParam(p int) Modifier {
+	if p < 2 || p > 16 {
+		return ModNone
+	}
+	return Modifier(p - 1)
+}
+
+// Event is a single key decoded from an input byte sequence.
+//
+// Event is a small value type that may be copied freely. It never refers to
+// the buffer from which it was decoded; the original bytes are copied into the
+// Event itself.
+type Event struct {
+	Code  rune     // Control key code, or 0 if Event is an ordinary rune
+	Rune  rune     // Decoded UTF-8 rune, or 0 if Event is a control key
+	Mod   Modifier // Modifier keys held
+	X, Y  uint16   // Screen column and row (1-based), or window width and height
+	Count uint16   // Number of times the key was pressed, where 0 means 1
+	seq   [limits.MaxBytesPerKey]byte
+	size  uint8
+}
+
+// MakeEvent returns an Event equivalent to the given key code or rune k, as
+// returned by the rune-based API (e.g., Buffer.Parse).
+func MakeEvent(k rune) (ev Event) {
+	ev.Set(k)
+	switch k {
+	case AltLeft, AltRight:
+		ev.Mod = ModAlt
+	}
+	return
+}
+
+// Set sets the key code or rune of e from k, and clears all other fields.
+func (e *Event) Set(k rune) {
+	if e == nil {
+		return
+	}
+	*e = Event{}
+	if k == Error || k == Unknown || IsControl(k) {
+		e.Code = k
+	} else {
+		e.Rune = k
+	}
+}
+
+// Key returns e as a single rune compatible with the rune-based API.
+// This is e.Code if e is a control key, otherwise e.Rune.
+func (e *Event) Key() rune {
+	if e == nil {
+		return Error
+	}
+	if e.Code != 0 {
+		return e.Code
+	}
+	return e.Rune
+}
+
+// Is returns true if and only if e has key code or rune k and exactly the
+// modifiers mod.
+func (e *Event) Is(k rune, mod Modifier) bool {
+	return e != nil && e.Key() == k && e.Mod == mod
+}
+
+// Repeat returns the number of times the key of e was pressed, which is at least
+// 1 (see Count).
+func (e *Event) Repeat() int {
+	if e == nil || e.Count == 0 {
+		return 1
+	}
+	return int(e.Count)
+}
+
+// IsError returns true if and only if e is nil or no key could be decoded.
+func (e *Event) IsError() bool {
+	return e == nil || e.Code == Error
+}
+
+// IsControl returns true if and only if e is a control key.
+func (e *Event) IsControl() bool {
+	return e != nil && IsControl(e.Code)
+}
+
+// IsPrintable returns true if and only if e is a visible, non-whitespace key.
+func (e *Event) IsPrintable() bool {
+	return e != nil && e.Code == 0 && IsPrintable(e.Rune)
+}
+
+// Len returns the number of bytes in the original sequence of e.
+func (e *Event) Len() int {
+	if e == nil {
+		return 0
+	}
+	return int(e.size)
+}
+
+// Bytes returns the original byte sequence from which e was decoded.
+//
+// The returned slice refers to the storage of e and is only valid as long as e
+// is not modified.
+func (e *Event) Bytes() []byte {
+	if e == nil {
+		return nil
+	}
+	return e.seq[:e.size]
+}
+
+// SetBytes copies the original byte sequence p into e.
+// If p is longer than limits.MaxBytesPerKey, only the leading bytes are kept.
+func (e *Event) SetBytes(p []byte) {
+	if e == nil {
+		return
+	}
+	e.size = uint8(copy(e.seq[:], p))
+}
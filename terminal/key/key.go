@@ -60,6 +60,15 @@ const (
 	F20
 	Interrupt
 	EndOfFile
+	MouseLeft
+	MouseMiddle
+	MouseRight
+	MouseRelease
+	MouseWheelUp
+	MouseWheelDown
+	CursorPosition
+	QuotedInsert
+	WindowSize
 	surrogateMask = Unknown | 0x03FF
 )
 
@@ -68,7 +77,25 @@ func IsControl(key rune) bool {
 	return Unknown < key && key < surrogateMask
 }
 
+// IsMouse returns true iff key is a mouse event code.
+func IsMouse(key rune) bool {
+	return MouseLeft <= key && key <= MouseWheelDown
+}
+
 // IsPrintable returns true iff key is a visible, non-whitespace key.
+// The C0 and C1 control characters, and DEL, are not printable.
 func IsPrintable(key rune) bool {
-	return key >= ansi.Space && !IsControl(key)
+	return key >= ansi.Space && key != ansi.Backspace &&
+		(key < 0x80 || key >= 0xA0) && !IsControl(key)
+}
+
+// IsRepeatable returns true iff key is a control key code whose repeated presses
+// may be applied as a single key with a repeat count, e.g., cursor movement and
+// erasing keys held down.
+func IsRepeatable(key rune) bool {
+	switch key {
+	case Up, Down, Left, Right, AltLeft, AltRight, Backspace, Delete, DeleteWord:
+		return true
+	}
+	return false
 }
@@ -0,0 +1,107 @@
+package line
+
+import (
+	"github.com/ardnew/embedit/seq/ascii"
+	"github.com/ardnew/embedit/seq/utf8"
+)
+
+// tabWidth is the number of columns between tab stops.
+const tabWidth = 8
+
+// caretMark is drawn before the caret notation of a control character, e.g.,
+// ^C for ETX (0x03) and ^? for DEL (0x7F).
+const caretMark = '^'
+
+// isCaret returns true if and only if r is a C0 control character other than
+// tab, or DEL, which are drawn in caret notation.
+func isCaret(r rune) bool {
+	return r < ascii.SP && r != ascii.TAB || r == ascii.DEL
+}
+
+// isCodePoint returns true if and only if r is a C1 control character, which is
+// drawn as its code point, e.g., <U+0085> for NEL, since terminals may
+// interpret it as a control function even when encoded in UTF-8.
+func isCodePoint(r rune) bool {
+	return 0x80 <= r && r < 0xA0
+}
+
+// codePoint is the template of the notation drawn for a C1 control character,
+// whose last two digits are replaced with the low byte of the code point.
+var codePoint = [...]byte{'<', 'U', '+', '0', '0', '0', '0', '>'}
+
+// hexDigit contains the uppercase hexadecimal digits.
+const hexDigit = "0123456789ABCDEF"
+
+// runeWidth returns the number of columns occupied by r when drawn at column x
+// of a row with the given number of columns.
+//
+// A C0 control character occupies two columns in caret notation, a C1 control
+// character occupies the columns of its code point notation, and a tab extends
+// to the next tab stop, or to the end of the row. All other runes occupy the
+// number of columns given by utf8.Width.
+func runeWidth(r *utf8.Rune, x, columns int) int {
+	switch c := r.Rune(); {
+	case c == ascii.TAB:
+		if columns > 0 {
+			x %= columns
+		}
+		next := (x/tabWidth + 1) * tabWidth
+		if columns > 0 && next > columns {
+			next = columns
+		}
+		if next <= x {
+			return 1
+		}
+		return next - x
+	case isCaret(c):
+		return 2
+	case isCodePoint(c):
+		return len(codePoint)
+	}
+	return r.Width()
+}
+
+// putLen returns the number of bytes appended by put.
+func putLen(r *utf8.Rune, width int) int {
+	switch c := r.Rune(); {
+	case c == ascii.TAB:
+		return width
+	case isCaret(c):
+		return 2
+	case isCodePoint(c):
+		return len(codePoint)
+	}
+	return r.Len()
+}
+
+// put appends r to the output buffer, where width is the number of columns it
+// occupies (see runeWidth). A C0 control character is appended in caret
+// notation, a C1 control character as its code point, and a tab as blanks.
+// Runes with an invalid encoding are skipped.
+func (l *Line) put(r *utf8.Rune, width int) (err error) {
+	switch c := r.Rune(); {
+	case c == ascii.TAB:
+		for ; err == nil && width > 0; width-- {
+			err = l.ctrl.Out.WriteByte(ascii.SP)
+		}
+	case isCaret(c):
+		if err = l.ctrl.Out.WriteByte(caretMark); err == nil {
+			err = l.ctrl.Out.WriteByte(byte(c) ^ 0x40)
+		}
+	case isCodePoint(c):
+		n := len(codePoint)
+		for i := 0; err == nil && i < n; i++ {
+			b := codePoint[i]
+			switch i {
+			case n - 3:
+				b = hexDigit[c>>4&0xF]
+			case n - 2:
+				b = hexDigit[c&0xF]
+			}
+			err = l.ctrl.Out.WriteByte(b)
+		}
+	default:
+		_, _ = l.ctrl.Out.ReadFrom(r)
+	}
+	return
+}
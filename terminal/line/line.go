@@ -6,6 +6,7 @@ import (
 	"github.com/ardnew/embedit/config/limits"
 	"github.com/ardnew/embedit/errors"
 	"github.com/ardnew/embedit/seq/ansi"
+	"github.com/ardnew/embedit/seq/ascii"
 	"github.com/ardnew/embedit/seq/utf8"
 	"github.com/ardnew/embedit/terminal/cursor"
 	"github.com/ardnew/embedit/terminal/display"
@@ -20,6 +21,7 @@ type Line struct {
 	disp  *display.Display
 	Rune  [limits.RunesPerLine]utf8.Rune
 	posi  volatile.Register32
+	skip  volatile.Register32 // First visible position with horizontal scroll
 	head  volatile.Register32
 	tail  volatile.Register32
 	iter  utf8.Iterable
@@ -64,17 +66,19 @@ func (l *Line) Reset() *Line {
 	}
 	l.paste = false
 	l.posi.Set(0)
+	l.skip.Set(0)
 	l.head.Set(0)
 	l.tail.Set(0)
 	l.iter.Reset()
 	return l
 }
 
-// LineFeed flushes all data to output device and resets the cursor, data, and
-// I/O buffers to begin processing a new line.
+// LineFeed flushes all data to output device and resets the cursor and data to
+// begin processing a new line.
 func (l *Line) LineFeed() {
 	if l != nil && l.ctrl != nil && l.curs != nil {
 		l.Reset().curs.LineFeed()
+		l.disp.Model().Reset()
 	}
 }
 
@@ -201,6 +205,38 @@ func (l *Line) RuneCountToEndOfWord() (n int) {
 	return pos - from
 }
 
+// RuneCountToPreviousGrapheme returns the number of places from the cursor to
+// the start of the extended grapheme cluster preceding it.
+func (l *Line) RuneCountToPreviousGrapheme() (n int) {
+	from := l.Position()
+	head := int(l.head.Get())
+	start := 0
+	var g utf8.Graphemes
+	for pos := 0; pos < from; pos++ {
+		if g.Break(rune(*l.RuneAt(head + pos))) {
+			start = pos
+		}
+	}
+	return from - start
+}
+
+// RuneCountToNextGrapheme returns the number of places from the cursor to the
+// end of the extended grapheme cluster following it.
+func (l *Line) RuneCountToNextGrapheme() (n int) {
+	from := l.Position()
+	head := int(l.head.Get())
+	eol := l.RuneCount()
+	var g utf8.Graphemes
+	// Scan from the start of line, since the rules for some grapheme clusters
+	// (e.g., flag sequences) depend on all runes preceding the cursor.
+	for pos := 0; pos < eol; pos++ {
+		if g.Break(rune(*l.RuneAt(head + pos))) && pos > from {
+			return pos - from
+		}
+	}
+	return eol - from
+}
+
 // InsertRune inserts key at the current cursor position in l.
 func (l *Line) InsertRune(key rune) (err error) {
 	if l == nil || !l.valid {
@@ -212,26 +248,30 @@ func (l *Line) InsertRune(key rune) (err error) {
 	}
 	l.tail.Set(t + 1)
 	pos := l.Position()
-	end := int(t)
+	// Shift the runes at and after the cursor right by one, starting with the
+	// last rune. The slot following the last rune is unused, and it must not be
+	// copied: when the line is full, that slot wraps around to the first rune.
+	end := int(t) - 1
 	for end-(int(h)+pos) >= 0 {
 		l.RuneAt(int(end) + 1).Set(*l.RuneAt(int(end)))
 		end--
 	}
 	l.RuneAt(int(h) + pos).SetRune(key)
-	if l.disp.Echo() {
-		// Temporarily adjust head to rewrite only the changed portion of text.
-		l.head.Set(h + uint32(pos))
-		// Write out the text right-of the insertion.
-		err = l.Flush()
-		// Reset head back to the actual beginning of the line.
-		l.head.Set(h)
-	}
-	if e := l.MoveCursorTo(int(pos) + 1); err == nil && e != nil {
-		err = e
+	return l.update(pos + 1)
+}
+
+// AppendRune appends key to the end of l without updating the display, e.g.,
+// when reading lines of input that are not echoed.
+func (l *Line) AppendRune(key rune) (err error) {
+	if l == nil || !l.valid {
+		return &errors.ErrInvalidReceiver
 	}
-	if l.flush {
-		l.ctrl.Flush()
+	h, t := l.head.Get(), l.tail.Get()
+	if t-h >= limits.RunesPerLine {
+		return &errors.ErrWriteOverflow
 	}
+	l.RuneAt(int(t)).SetRune(key)
+	l.tail.Set(t + 1)
 	return
 }
 
@@ -240,7 +280,7 @@ func (l *Line) InsertRune(key rune) (err error) {
 // erased.
 //
 // Appends sequences to the output buffer for repositioning the cursor and
-// overwriting the portion of text that changed.
+// redrawing the portion of text that changed.
 func (l *Line) ErasePreviousRuneCount(n int) (err error) {
 	if l == nil || !l.valid {
 		return &errors.ErrInvalidReceiver
@@ -253,9 +293,6 @@ func (l *Line) ErasePreviousRuneCount(n int) (err error) {
 		n = pos
 	}
 	pos -= n
-	if err = l.MoveCursorTo(pos); err != nil {
-		return err
-	}
 	// Overwrite leading runes with trailing runes
 	h, t := l.head.Get(), l.tail.Get()-uint32(n)
 	if hs, s := h, l.iter.Slice(pos+n, -1); s != nil {
@@ -268,35 +305,33 @@ func (l *Line) ErasePreviousRuneCount(n int) (err error) {
 			hs++
 		}
 	}
-	// Erase the trailing runes with spaces
-	for i := 0; i < n; i++ {
-		l.RuneAt(int(t + uint32(i))).Set(' ')
-	}
-	if l.disp.Echo() {
-		// Temporarily adjust head to rewrite only the changed portion of text.
-		l.head.Set(h + uint32(pos))
-		// Write out the text right-of the deletion, including the 0x20 erasors.
-		err = l.Flush()
-		// Reset head back to the actual beginning of the line.
-		l.head.Set(h)
-	}
-	// Finally truncate tail, set final cursor position, and flush output buffer.
+	// Truncate tail to exclude the erased runes.
 	l.tail.Set(t)
-	if e := l.MoveCursorTo(pos); err == nil && e != nil {
-		err = e
-	}
-	if l.flush {
-		l.ctrl.Flush()
-	}
-	return
+	return l.update(pos)
 }
 
+// ClearScreen appends the sequences to the output buffer that erase the display
+// and move the cursor to the home position.
+//
+// Dumb terminals are unable to erase the display, so the cursor is moved to the
+// start of a new row instead.
 func (l *Line) ClearScreen() (err error) {
+	if l.disp.Dumb() {
+		_, err = l.ctrl.Out.WriteEOL()
+		l.curs.Set(0, 0)
+		l.disp.Model().Reset()
+		if l.flush {
+			l.ctrl.Flush()
+		}
+		return
+	}
 	_, err = l.ctrl.Out.Write(ansi.CLS)
 	if _, e := l.ctrl.Out.Write(ansi.XY0); err == nil && e != nil {
 		err = e
 	}
 	l.curs.Set(0, 0)
+	l.curs.SetOrigin(1)
+	l.disp.Model().Reset()
 	if l.flush {
 		l.ctrl.Flush()
 	}
@@ -328,6 +363,57 @@ func (l *Line) GlyphCount() (count int) {
 	return l.glyphCount(0, -1)
 }
 
+// width returns the number of columns occupied by the runes in l from k to
+// k+n-1, drawn in a single row starting at display column col. If n is
+// negative, returns the number of columns occupied by the runes in l starting
+// at k.
+//
+// Like glyphCount, k = 0 always refers to head.
+func (l *Line) width(k, n, col int) (width int) {
+	if n < 0 {
+		n = l.RuneCount() - k
+	}
+	h := int(l.head.Get())
+	w := l.disp.Width()
+	for i := k; i < k+n; i++ {
+		width += runeWidth(l.RuneAt(h+i), col+width, w)
+	}
+	return
+}
+
+// Width returns the number of columns occupied by the runes in l, drawn in a
+// single row following the prompt.
+func (l *Line) Width() int {
+	return l.width(0, -1, l.disp.PromptWidth())
+}
+
+// column returns the display column — counted from the start of the prompt,
+// continuing across rows — at which the rune at the given logical position in
+// the text of l is drawn.
+//
+// A wide rune that does not fit in the remaining columns of a row is drawn at
+// the start of the next row, as done by Flush. Control characters occupy the
+// columns of their caret or code point notation, and tabs extend to the next tab
+// stop.
+func (l *Line) column(position int) (col int) {
+	w := l.disp.Width()
+	h := int(l.head.Get())
+	col = l.disp.PromptWidth()
+	for i := 0; i < position; i++ {
+		col = advance(col, runeWidth(l.RuneAt(h+i), col, w), w)
+	}
+	return
+}
+
+// advance returns the display column following a glyph of the given width drawn
+// at column col on a display with the given number of columns per row.
+func advance(col, width, columns int) int {
+	if x := col % columns; x+width > columns {
+		col += columns - x
+	}
+	return col + width
+}
+
 // Position returns the logical cursor Position in the text of l.
 //
 // At Position 0, the cursor is located on the first rune in l wherever the text
@@ -348,40 +434,51 @@ func (l *Line) setPosition(position int) int {
 }
 
 // moveCursorTo appends key sequences to the output buffer that move the cursor
-// to the given logical position in the text, adjusted by offset, and updates
-// l's logical cursor position and the cursor's X, Y coordinates. The offset is
-// typically the number of places in — or width of — the input prompt.
-func (l *Line) moveCursorTo(position, offset int) (err error) {
-	x := offset + l.setPosition(position)
+// to the given logical position in the text, and updates l's logical cursor
+// position and the cursor's X, Y coordinates.
+func (l *Line) moveCursorTo(position int) (err error) {
+	if l.disp.HorizontalScroll() {
+		return l.scrollTo(position, false)
+	}
+	x := l.column(l.setPosition(position))
 	if !l.disp.Echo() {
 		return
 	}
 	w := l.disp.Width()
-	y := x / w
-	x %= w
-	xc, yc := l.curs.Get()
-	var du, dd, dl, dr int
-	if y < yc {
-		du = yc - y
-	}
-	if y > yc {
-		dd = y - yc
-	}
-	if x < xc {
-		dl = xc - x
-	}
-	if x > xc {
-		dr = x - xc
-	}
-	_, _ = l.curs.Set(x, y)
-	return l.curs.Move(du, dd, dl, dr)
+	return l.curs.MoveTo(x%w, x/w)
 }
 
 // MoveCursorTo appends key sequences to the output buffer that move the cursor
 // to the given logical position in the text, updating l's logical cursor
 // position and the cursor's X, Y coordinates.
 func (l *Line) MoveCursorTo(position int) (err error) {
-	return l.moveCursorTo(position, l.disp.GlyphCountInPrompt())
+	return l.moveCursorTo(position)
+}
+
+// PositionAt returns the logical cursor position in the text of l that is
+// nearest to the given X, Y coordinates, which are relative to the first row of
+// the line (see Cursor.ScreenToLine).
+func (l *Line) PositionAt(x, y int) int {
+	if l == nil || !l.valid {
+		return 0
+	}
+	if l.disp.HorizontalScroll() {
+		return l.scrollPositionAt(x)
+	}
+	w := l.disp.Width()
+	if x >= w {
+		x = w - 1
+	}
+	want := y*w + x
+	h := int(l.head.Get())
+	col := l.disp.PromptWidth()
+	end := l.RuneCount()
+	for i := 0; i < end; i++ {
+		if col = advance(col, runeWidth(l.RuneAt(h+i), col, w), w); want < col {
+			return i
+		}
+	}
+	return end
 }
 
 // MoveCursor appends sequences to the output buffer that move the cursor by the
@@ -407,45 +504,33 @@ func (l *Line) SetAndMoveCursorTo(s []rune, position int) (err error) {
 		err = &errors.ErrWriteOverflow
 		s = s[:limits.RunesPerLine]
 	}
-	prev := l.RuneCount()
 	curr := len(s)
 	l.Reset()
 	for i := range s {
 		l.Rune[i].SetRune(s[i])
 	}
-	tail := 0
-	for tail = curr; tail < prev; tail++ {
-		l.Rune[tail].Set(' ')
-	}
-	if l.disp.Echo() {
-		if e := l.MoveCursorTo(0); err == nil && e != nil {
-			err = e
-		}
-		l.tail.Set(uint32(tail))
-		if e := l.Flush(); err == nil && e != nil {
-			err = e
-		}
-	}
 	l.tail.Set(uint32(curr))
 	if position < 0 {
 		// Position cursor at end of line if pos is negative.
 		position = curr
 	}
-	if e := l.MoveCursorTo(position); err == nil && e != nil {
+	if e := l.update(position); err == nil && e != nil {
 		err = e
 	}
-	if l.flush {
-		l.ctrl.Flush()
-	}
 	return
 }
 
 // ShowPrompt appends the user input prompt to the output buffer, positions the
 // cursor at the end of the prompt, and flushes the buffer to the output device.
+// The prompt is first generated by the display's Prompter, if any.
+//
+// If the prompt contains newlines, the rows preceding its last row are drawn
+// above the line, and the last row is drawn on the line's first row (Y=0).
 func (l *Line) ShowPrompt() (err error) {
 	if l == nil || !l.valid {
 		return &errors.ErrInvalidReceiver
 	}
+	l.disp.UpdatePrompt()
 	// Iterate over prompt elements as Rune elements (instead of native rune),
 	// because it implements an unbuffered io.Reader for copying bytes in each
 	// UTF-8 code point.
@@ -458,47 +543,172 @@ func (l *Line) ShowPrompt() (err error) {
 			break
 		}
 	}
-	if l.curs.Update(s.Reset().GlyphCount()) {
+	// Y=0 is the row on which the last row of the prompt is drawn.
+	l.curs.ShiftOrigin(l.disp.PromptRows())
+	if l.curs.Update(l.disp.PromptWidth()) {
 		// If the cursor would write beyond the terminal width (line wrap), then
 		// also append CR+LF to the output buffer.
 		_, _ = l.ctrl.Out.WriteEOL()
 	}
+	// Nothing has been drawn following the new prompt.
+	l.disp.Model().Reset()
 	return l.Flush()
 }
 
-// Flush copies all runes in l to the output buffer and advances the cursor's
-// current position to the end of the line.
-func (l *Line) Flush() (err error) {
-	width := l.disp.Width()
-	h, t := l.head.Get(), l.tail.Get()
-	for t-h > 0 {
-		free := width - l.curs.X()
-		have := int(t - h)
-		want := have
-		if want > free {
-			want = free
+// Redraw appends the sequences to the output buffer that move the cursor to the
+// start of the prompt, erase the prompt and text of l from the display, and then
+// draw them again.
+//
+// Redraw is used when the contents of the display are unknown, e.g., after
+// output not written by l has moved the cursor or overwritten the line.
+//
+// Dumb terminals are unable to erase the display, so the prompt and text of l
+// are instead reprinted over the current row, and the remaining columns of the
+// row are overwritten with spaces.
+func (l *Line) Redraw() (err error) {
+	if l == nil || !l.valid {
+		return &errors.ErrInvalidReceiver
+	}
+	if l.disp.Dumb() {
+		return l.reprint()
+	}
+	if err = l.curs.MoveTo(0, 0); err != nil {
+		return
+	}
+	// Move to the first row of a prompt drawn on multiple rows.
+	if rows := l.disp.PromptRows(); rows > 0 {
+		if err = l.curs.Move(rows, 0, 0, 0); err != nil {
+			return
 		}
-		var seen, kept int
-		// Copy the bytes in each rune of l to the output buffer, skipping any runes
-		// with an invalid encoding.
-		for ; kept < want && seen < have; seen++ {
-			if _, errc := l.ctrl.Out.ReadFrom(l.RuneAt(int(h) + seen)); errc == nil {
-				kept++
-			}
+		l.curs.ShiftOrigin(-rows)
+	}
+	if err = l.curs.EraseBelow(); err != nil {
+		return
+	}
+	return l.ShowPrompt()
+}
+
+// reprint appends a CR, the last row of the prompt, and the visible text of l to
+// the output buffer, overwriting the current row of a dumb terminal.
+func (l *Line) reprint() (err error) {
+	l.disp.UpdatePrompt()
+	if err = l.curs.MoveTo(0, 0); err != nil {
+		return
+	}
+	s := l.disp.PromptIterable().Reset()
+	if s == nil {
+		return &errors.ErrInvalidArgument
+	}
+	// Find the first rune of the last row of the prompt.
+	row := 0
+	for i, n := 0, s.Count(); i < n; i++ {
+		if s.Next().EqualsRune(ascii.LF) {
+			row = i + 1
 		}
-		// Update the cursor's coordinates based on the number of valid, visible
-		// runes written to the output buffer.
-		if l.curs.Update(l.glyphCount(0, seen)) {
-			// If the cursor would write beyond the terminal width (line wrap), then
-			// also append CR+LF to the output buffer.
-			if _, err = l.ctrl.Out.WriteEOL(); err != nil {
-				return
+	}
+	if s = s.Slice(row, -1); s != nil {
+		for {
+			if _, rerr := l.ctrl.Out.ReadFrom(s.Next()); rerr != nil {
+				break
 			}
 		}
-		h += uint32(seen)
 	}
-	if l.flush {
-		l.ctrl.Flush()
+	l.curs.Update(l.disp.PromptWidth())
+	l.disp.Model().Reset()
+	return l.Flush()
+}
+
+// Flush appends the sequences to the output buffer that draw the runes in l
+// which differ from those on the display, and moves the cursor to the logical
+// cursor position.
+//
+// With horizontal scrolling, only the visible portion of the line is drawn.
+func (l *Line) Flush() (err error) {
+	return l.update(l.Position())
+}
+
+// update appends the sequences to the output buffer that redraw the portion of
+// text in l that changed, and moves the cursor to the given logical position.
+//
+// The suggestion following the text is updated first, since the text may have
+// changed.
+func (l *Line) update(position int) (err error) {
+	if l.disp.HorizontalScroll() {
+		return l.scrollTo(position, true)
+	}
+	l.disp.Suggest(l.iter.Reset(), l.RuneCount())
+	return l.render(position)
+}
+
+// AcceptSuggestion appends the first n runes of the suggestion following the
+// text of l, or all of them if n is negative, and moves the cursor to the end
+// of l.
+func (l *Line) AcceptSuggestion(n int) (err error) {
+	if l == nil || !l.valid {
+		return &errors.ErrInvalidReceiver
+	}
+	s := l.disp.Suggestion()
+	if n < 0 || n > len(s) {
+		n = len(s)
+	}
+	h, t := l.head.Get(), l.tail.Get()
+	if room := limits.RunesPerLine - int(t-h); n > room {
+		n = room
+	}
+	for i := 0; i < n; i++ {
+		l.RuneAt(int(t) + i).SetRune(s[i])
+	}
+	l.tail.Set(t + uint32(n))
+	return l.update(l.RuneCount())
+}
+
+// DismissSuggestion erases the suggestion following the text of l, if any,
+// e.g., before the line is entered.
+func (l *Line) DismissSuggestion() (err error) {
+	if l == nil || !l.valid {
+		return &errors.ErrInvalidReceiver
+	}
+	if l.disp.Suggestion() == nil {
+		return
+	}
+	l.disp.ClearSuggestion()
+	return l.render(l.Position())
+}
+
+// RuneCountToEndOfSuggestedWord returns the number of runes from the start of
+// the suggestion following the text of l to the end of its first word.
+func (l *Line) RuneCountToEndOfSuggestedWord() (n int) {
+	s := l.disp.Suggestion()
+	for n < len(s) && s[n] == ' ' {
+		n++
+	}
+	for n < len(s) && s[n] != ' ' {
+		n++
+	}
+	return
+}
+
+// advance updates the cursor's coordinates after n columns of glyphs have been
+// appended to the output buffer.
+func (l *Line) advance(n int) (err error) {
+	if l.curs.Update(n) {
+		// If the cursor would write beyond the terminal width (line wrap), then
+		// also append CR+LF to the output buffer.
+		_, err = l.ctrl.Out.WriteEOL()
+	}
+	return
+}
+
+// pad appends n spaces to the output buffer and advances the cursor's current
+// position accordingly.
+func (l *Line) pad(n int) (err error) {
+	for ; n > 0; n-- {
+		if err = l.ctrl.Out.WriteByte(ansi.Space); err != nil {
+			return
+		}
+		if err = l.advance(1); err != nil {
+			return
+		}
 	}
 	return
 }
@@ -0,0 +1,199 @@
+package line
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ardnew/embedit/seq"
+	"github.com/ardnew/embedit/seq/eol"
+	"github.com/ardnew/embedit/seq/utf8"
+	"github.com/ardnew/embedit/terminal/cursor"
+	"github.com/ardnew/embedit/terminal/display"
+	"github.com/ardnew/embedit/terminal/wire"
+	"github.com/google/go-cmp/cmp"
+)
+
+// screen contains a Line and the objects it draws with. All output flushed by
+// the Line is copied to out.
+type screen struct {
+	ctrl wire.Control
+	in   seq.Buffer
+	buf  seq.Buffer
+	disp display.Display
+	curs cursor.Cursor
+	line Line
+	out  strings.Builder
+}
+
+// newScreen returns a screen with the given width, on which the prompt has been
+// drawn. All output drawn so far is discarded.
+func newScreen(width int, prompt string) *screen {
+	s := &screen{}
+	s.line.Configure(false, s.curs.Configure(false,
+		s.ctrl.Configure(s, s.in.Configure(eol.Auto), s.buf.Configure(eol.CRLF)),
+		s.disp.Configure(width, 24, []rune(prompt), true)))
+	_ = s.disp.EnablePrompt(true)
+	_ = s.line.ShowPrompt()
+	_ = s.drain()
+	return s
+}
+
+// Swell implements wire.Controller. No input is ever received.
+func (s *screen) Swell() (int, error) { return 0, io.EOF }
+
+// Flush implements wire.Controller.
+func (s *screen) Flush() (int, error) {
+	n, err := s.buf.WriteTo(&s.out)
+	return int(n), err
+}
+
+// drain returns all output appended by the Line since the last call to drain.
+func (s *screen) drain() string {
+	_, _ = s.Flush()
+	out := s.out.String()
+	s.out.Reset()
+	return out
+}
+
+// insert inserts each rune of text at the cursor.
+func (s *screen) insert(text string) {
+	for _, r := range text {
+		_ = s.line.InsertRune(r)
+	}
+}
+
+func TestLine_Wrap(t *testing.T) {
+	t.Parallel()
+	type pos struct{ Position, X, Y int }
+	for name, tt := range map[string]struct {
+		width   int
+		text    string
+		want    pos    // Cursor at end of text
+		clicks  []pos  // Position at X, Y
+		wantOut string // Output of Flush after the display model is reset
+		wcwidth bool   // Requires the width tables (no build tag "nowcwidth")
+	}{
+		"narrow-fits": {
+			// The last column of the first row is filled exactly.
+			width: 10, text: "abcdefgh",
+			want:    pos{8, 0, 1},
+			clicks:  []pos{{7, 9, 0}, {8, 0, 1}, {8, 5, 1}},
+			wantOut: "abcdefgh\r\r\n",
+		},
+		"wide-at-edge": {
+			// A wide rune that does not fit in the last column is drawn at the
+			// start of the next row, and the last column is left blank.
+			width: 10, text: "abcdefg漢",
+			want:    pos{8, 2, 1},
+			clicks:  []pos{{6, 8, 0}, {7, 9, 0}, {7, 0, 1}, {7, 1, 1}, {8, 2, 1}},
+			wantOut: "abcdefg \r\r\n漢",
+			wcwidth: true,
+		},
+		"wide-fills-row": {
+			width: 10, text: "abcdef漢",
+			want:    pos{7, 0, 1},
+			clicks:  []pos{{6, 8, 0}, {6, 9, 0}, {7, 0, 1}},
+			wantOut: "abcdef漢\r\r\n",
+			wcwidth: true,
+		},
+		"wide-each-row": {
+			width: 6, text: "漢字漢字",
+			want:    pos{4, 4, 1},
+			clicks:  []pos{{0, 2, 0}, {1, 4, 0}, {1, 5, 0}, {2, 0, 1}, {3, 3, 1}, {4, 4, 1}},
+			wantOut: "漢字\r\r\n漢字",
+			wcwidth: true,
+		},
+		"combining-at-edge": {
+			// A zero-width rune following the last column combines with it.
+			width: 10, text: "abcdefgh́i",
+			want:    pos{10, 1, 1},
+			clicks:  []pos{{7, 9, 0}, {9, 0, 1}},
+			wantOut: "abcdefgh́\r\r\ni",
+			wcwidth: true,
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if tt.wcwidth && utf8.Width('漢') != 2 {
+				t.Skip("width tables not compiled")
+			}
+			s := newScreen(tt.width, "> ")
+			s.insert(tt.text)
+			got := pos{s.line.Position(), s.curs.X(), s.curs.Y()}
+			if diff := cmp.Diff(tt.want, got); len(diff) > 0 {
+				t.Errorf("diff cursor (-want +got):%s\n", diff)
+			}
+			for _, c := range tt.clicks {
+				if p := s.line.PositionAt(c.X, c.Y); p != c.Position {
+					t.Errorf("PositionAt(%d, %d) = %d, want %d", c.X, c.Y, p, c.Position)
+				}
+			}
+			// Redraw the line from scratch to compare the complete layout.
+			s.disp.Model().Reset()
+			_ = s.curs.MoveTo(2, 0)
+			_ = s.drain()
+			_ = s.line.Flush()
+			out := s.drain()
+			if diff := cmp.Diff(tt.wantOut, out); len(diff) > 0 {
+				t.Errorf("diff output (-want +got):%s\n", diff)
+			}
+		})
+	}
+}
+
+// step is an action applied to the Line of a screen, and the output expected.
+type step struct {
+	do   func(l *Line) error
+	want string
+}
+
+// insert returns an action that inserts each rune of text at the cursor.
+func insert(text string) func(l *Line) error {
+	return func(l *Line) (err error) {
+		for _, r := range text {
+			if err = l.InsertRune(r); err != nil {
+				return
+			}
+		}
+		return
+	}
+}
+
+// erase returns an action that erases n runes preceding the cursor.
+func erase(n int) func(l *Line) error {
+	return func(l *Line) error { return l.ErasePreviousRuneCount(n) }
+}
+
+// moveTo returns an action that moves the cursor to the given position.
+func moveTo(position int) func(l *Line) error {
+	return func(l *Line) error { return l.MoveCursorTo(position) }
+}
+
+// set returns an action that replaces the text of the line, as done when
+// recalling history.
+func set(text string) func(l *Line) error {
+	return func(l *Line) error { return l.Set([]rune(text)) }
+}
+
+// right returns an action that sets the right prompt and updates the display.
+func right(text string) func(l *Line) error {
+	return func(l *Line) error {
+		l.disp.SetRightPrompt([]rune(text))
+		return l.Flush()
+	}
+}
+
+// run applies each step to s, and reports the steps whose output differs.
+func (s *screen) run(t *testing.T, steps []step) {
+	t.Helper()
+	for i, st := range steps {
+		if err := st.do(&s.line); err != nil {
+			t.Errorf("step %d: unexpected error: %v", i, err)
+		}
+		if diff := cmp.Diff(st.want, s.drain()); len(diff) > 0 {
+			t.Errorf("step %d: diff output (-want +got):%s\n", i, diff)
+		}
+	}
+}
@@ -0,0 +1,458 @@
+package line
+
+import (
+	"github.com/ardnew/embedit/seq/ansi"
+	"github.com/ardnew/embedit/seq/utf8"
+	"github.com/ardnew/embedit/terminal/display"
+)
+
+// glyph is a unit of the layout of a Line on the display: a rune occupying one
+// or more columns together with all adjacent zero-width runes, preceded by any
+// blank columns padded because the glyph does not fit in the remaining columns
+// of a row.
+type glyph struct {
+	lo, hi int          // Logical positions of the runes in glyph
+	cell   int          // Index of the first display cell occupied by glyph
+	pad    int          // Number of blank cells preceding cell
+	width  int          // Number of cells occupied by glyph
+	val    display.Cell // Identity of glyph for comparison
+}
+
+// at returns the cell at index k, which must be in the range of cells covered
+// by g, including its padding.
+func (g *glyph) at(k int) display.Cell {
+	switch {
+	case k < g.cell:
+		return display.CellBlank
+	case k > g.cell:
+		return display.CellWide
+	}
+	return g.val
+}
+
+// start returns the index of the first cell covered by g, including padding.
+func (g *glyph) start() int { return g.cell - g.pad }
+
+// end returns the index of the cell following g.
+func (g *glyph) end() int { return g.cell + g.width }
+
+// layout iterates over the glyphs of a Line in the order they are drawn, using
+// the same rules as Flush and column. The glyphs of the suggestion, if any,
+// follow the glyphs of the text.
+type layout struct {
+	l    *Line
+	g    glyph
+	size int // Number of runes in the line and suggestion
+	col  int // Display column of the first cell
+	cols int // Number of columns per row
+}
+
+// reset prepares it to iterate over the glyphs of l.
+func (it *layout) reset(l *Line) *layout {
+	*it = layout{
+		l:    l,
+		size: l.RuneCount() + len(l.disp.Suggestion()),
+		col:  l.disp.PromptWidth(),
+		cols: l.disp.Width(),
+	}
+	return it
+}
+
+// next advances it to the next glyph, and returns false if and only if there
+// are no glyphs remaining.
+func (it *layout) next() bool {
+	g := &it.g
+	if g.hi >= it.size {
+		return false
+	}
+	cell := g.end()
+	x := (it.col + cell) % it.cols
+	g.lo, g.pad, g.width, g.val = g.hi, 0, 0, display.CellBlank
+	// Zero-width runes only precede a glyph's rune at the start of the line;
+	// everywhere else, they are combined with the preceding glyph.
+	for g.hi < it.size && g.width == 0 {
+		r := it.l.runeAt(g.hi)
+		if g.width = runeWidth(r, x, it.cols); g.hi == g.lo {
+			g.val = display.MakeCell(rune(*r))
+		} else {
+			g.val = g.val.Combine(rune(*r))
+		}
+		g.hi++
+	}
+	for g.hi < it.size {
+		r := it.l.runeAt(g.hi)
+		if runeWidth(r, x, it.cols) != 0 {
+			break
+		}
+		g.val = g.val.Combine(rune(*r))
+		g.hi++
+	}
+	// Glyphs drawn with different styles are different.
+	if s := it.l.styleAt(g.lo); !s.IsDefault() {
+		g.val = g.val.Combine(rune(s.Fg)).Combine(rune(s.Bg)).Combine(rune(s.Attr))
+	}
+	if x+g.width > it.cols {
+		g.pad = it.cols - x
+	}
+	g.cell = cell + g.pad
+	return true
+}
+
+// cellAt returns the X, Y coordinates of the display cell at index k.
+func (l *Line) cellAt(k int) (x, y int) {
+	w := l.disp.Width()
+	col := l.disp.PromptWidth() + k
+	return col % w, col / w
+}
+
+// render appends the sequences to the output buffer that update the display to
+// show the text of l with the cursor at the given logical position, and updates
+// l's logical cursor position and the cursor's X, Y coordinates.
+//
+// Only the cells that differ from the display's model are rewritten. Cells are
+// shifted in place with ICH and DCH instead, if the terminal supports them and
+// all cells affected are in a single row.
+//
+// The right prompt is drawn if the text leaves room for it in the first row,
+// and otherwise erased before the text is drawn over it.
+//
+// Each glyph is drawn with the style given by the display's Highlighter, and
+// the default style is restored afterward.
+func (l *Line) render(position int) (err error) {
+	position = l.setPosition(position)
+	if !l.disp.Echo() {
+		return
+	}
+	l.disp.Highlight(l.iter.Reset())
+	l.disp.ResetStyle()
+	m := l.disp.Model()
+	// Find the first cell that differs from the model, and the new number of
+	// cells in the line.
+	lo, size := -1, 0
+	var it layout
+	for it.reset(l); it.next(); {
+		for k := it.g.start(); lo < 0 && k < it.g.end(); k++ {
+			if it.g.at(k) != m.At(k) {
+				lo = k
+			}
+		}
+		size = it.g.end()
+	}
+	if lo < 0 && size < m.Len() {
+		lo = size
+	}
+	right := l.rightColumn(size)
+	if drawn := m.RightPrompt(); drawn >= 0 && drawn != right {
+		err = l.eraseRight()
+	}
+	if lo >= 0 {
+		if ok, e := l.shift(lo, size); !ok {
+			e = l.redraw(lo, size)
+			if err == nil && e != nil {
+				err = e
+			}
+		} else if err == nil && e != nil {
+			err = e
+		}
+	}
+	if e := l.unstyle(); err == nil && e != nil {
+		err = e
+	}
+	if right >= 0 && m.RightPrompt() != right {
+		if e := l.drawRight(right); err == nil && e != nil {
+			err = e
+		}
+	}
+	x, y := l.cellAt(l.column(position) - l.disp.PromptWidth())
+	if e := l.curs.MoveTo(x, y); err == nil && e != nil {
+		err = e
+	}
+	if l.flush {
+		l.ctrl.Flush()
+	}
+	return
+}
+
+// shift updates the display using ICH or DCH to shift the cells following the
+// first changed cell lo, and returns true if and only if the display was
+// updated. The display is not updated if the terminal lacks the necessary
+// capability, the cells affected span multiple rows, the right prompt would be
+// shifted, or the cells following the insertion or deletion have otherwise
+// changed.
+func (l *Line) shift(lo, size int) (ok bool, err error) {
+	m := l.disp.Model()
+	caps := l.disp.Capabilities()
+	old := m.Len()
+	n := size - old
+	switch {
+	case lo >= old:
+		return false, nil // Nothing to shift
+	case m.RightPrompt() >= 0:
+		return false, nil // The right prompt would be shifted too
+	case n > 0 && caps&display.CapInsertChar != 0:
+	case n < 0 && caps&display.CapDeleteChar != 0:
+	default:
+		return false, nil
+	}
+	// The end of line both before and after must be in the row of lo, so that no
+	// cells are shifted across rows.
+	end := size
+	if old > end {
+		end = old
+	}
+	if _, y := l.cellAt(lo); y != (l.disp.PromptWidth()+end)/l.disp.Width() {
+		return false, nil
+	}
+	ins := 0
+	if n > 0 {
+		ins = n
+	}
+	var it layout
+	for it.reset(l); it.next(); {
+		g := &it.g
+		// The inserted cells must consist of whole glyphs.
+		if g.start() < lo && lo < g.end() ||
+			g.start() < lo+ins && lo+ins < g.end() {
+			return false, nil
+		}
+		for k := g.start(); k < g.end(); k++ {
+			if k >= lo+ins && g.at(k) != m.At(k-n) {
+				return false, nil
+			}
+		}
+	}
+	x, y := l.cellAt(lo)
+	if err = l.curs.MoveTo(x, y); err != nil {
+		return
+	}
+	if n < 0 {
+		err = l.curs.DeleteChars(-n)
+	} else if err = l.curs.InsertChars(n); err == nil {
+		for it.reset(l); err == nil && it.next(); {
+			if it.g.start() >= lo && it.g.end() <= lo+n {
+				err = l.draw(&it.g, it.g.start())
+			}
+		}
+	}
+	// Update the model with the shifted and inserted cells.
+	for it.reset(l); it.next(); {
+		for k := it.g.start(); k < it.g.end(); k++ {
+			if k >= lo {
+				m.Set(k, it.g.at(k))
+			}
+		}
+	}
+	m.SetLen(size)
+	return true, err
+}
+
+// redraw appends the glyphs of l that differ from the display's model at or
+// after the given cell lo to the output buffer, and then erases all cells of the
+// previous line beyond the given new number of cells.
+//
+// Unchanged cells are skipped by moving the cursor, unless rewriting them is
+// shorter.
+func (l *Line) redraw(lo, size int) (err error) {
+	m := l.disp.Model()
+	old := m.Len()
+	var it, gap layout
+	skipped := false // gap refers to the first glyph skipped since the last write
+	for it.reset(l); err == nil && it.next(); {
+		g := &it.g
+		if g.end() <= lo {
+			continue
+		}
+		from := -1
+		for k := g.start(); from < 0 && k < g.end(); k++ {
+			if g.at(k) != m.At(k) {
+				from = k
+			}
+		}
+		if from < 0 {
+			if !skipped {
+				gap, skipped = it, true
+			}
+			continue
+		}
+		if from > g.cell {
+			from = g.cell
+		}
+		if skipped && l.rewrite(&gap, g.lo) {
+			// Rewrite the short run of unchanged glyphs instead of moving the cursor.
+			for ; err == nil && gap.g.lo < g.lo; gap.next() {
+				err = l.draw(&gap.g, gap.g.start())
+			}
+		}
+		skipped = false
+		if err == nil {
+			if x, y := l.cellAt(from); x != l.curs.X() || y != l.curs.Y() {
+				err = l.curs.MoveTo(x, y)
+			}
+		}
+		if err == nil {
+			err = l.draw(g, from)
+		}
+		for k := g.start(); k < g.end(); k++ {
+			m.Set(k, g.at(k))
+		}
+	}
+	m.SetLen(size)
+	if err == nil && size < old {
+		if err = l.unstyle(); err != nil {
+			return
+		}
+		x, y := l.cellAt(size)
+		if err = l.curs.MoveTo(x, y); err != nil {
+			return
+		}
+		switch _, last := l.cellAt(old - 1); {
+		case last != y:
+			if err = l.curs.EraseBelow(); y == 0 {
+				m.SetRightPrompt(-1)
+			}
+		case y == 0 && m.RightPrompt() >= 0:
+			// Blank the cells preceding the right prompt instead of erasing it.
+			err = l.pad(old - size)
+		default:
+			_, err = l.ctrl.Out.Write(ansi.KIL)
+		}
+	}
+	return
+}
+
+// rightColumn returns the column at which the right prompt is drawn, or -1 if
+// there is no right prompt or the given number of cells of text leaves no room
+// for it in the first row. The cursor at the end of the text must also precede
+// the right prompt.
+//
+// Like horizontal scrolling, the last column of the display is never used. The
+// right prompt is not drawn with horizontal scrolling.
+func (l *Line) rightColumn(size int) int {
+	n := l.disp.RightPromptWidth()
+	col := l.disp.Width() - 1 - n
+	if n == 0 || l.disp.HorizontalScroll() || l.disp.PromptWidth()+size >= col {
+		return -1
+	}
+	return col
+}
+
+// drawRight appends the right prompt to the output buffer, starting from the
+// given column of the first row.
+func (l *Line) drawRight(col int) (err error) {
+	if err = l.curs.MoveTo(col, 0); err != nil {
+		return
+	}
+	s := l.disp.RightPromptIterable().Reset()
+	for {
+		if _, rerr := l.ctrl.Out.ReadFrom(s.Next()); rerr != nil {
+			break
+		}
+	}
+	l.curs.Update(s.Reset().Width())
+	l.disp.Model().SetRightPrompt(col)
+	return
+}
+
+// eraseRight appends a sequence to the output buffer that erases the right
+// prompt, along with all cells following the text last drawn, which precedes
+// the right prompt in the first row.
+func (l *Line) eraseRight() (err error) {
+	x, y := l.cellAt(l.disp.Model().Len())
+	if err = l.curs.MoveTo(x, y); err != nil {
+		return
+	}
+	_, err = l.ctrl.Out.Write(ansi.KIL)
+	l.disp.Model().SetRightPrompt(-1)
+	return
+}
+
+// rewrite returns true if and only if the glyphs from gap up to the rune at the
+// given logical position can be rewritten in fewer bytes than moving the cursor
+// over them.
+//
+// The glyphs must all be in the cursor's current row, starting at the cursor.
+func (l *Line) rewrite(gap *layout, position int) bool {
+	x, y := l.cellAt(gap.g.start())
+	if x != l.curs.X() || y != l.curs.Y() {
+		return false
+	}
+	it := *gap
+	cost, n := 0, 0
+	for ; it.g.lo < position; it.next() {
+		if _, yg := l.cellAt(it.g.end() - 1); yg != y {
+			return false
+		}
+		for i := it.g.lo; i < it.g.hi; i++ {
+			cost += putLen(l.runeAt(i), it.g.width)
+		}
+		cost += it.g.pad
+		n = it.g.end() - gap.g.start()
+	}
+	// Moving right by n columns with CUF requires 3 bytes, plus the number of
+	// decimal digits in n if n > 1.
+	move := 3
+	for m := n; n > 1 && m > 0; m /= 10 {
+		move++
+	}
+	return cost <= move
+}
+
+// draw appends the glyph g to the output buffer, starting from the given cell,
+// which must be either the first cell of g or one of its padding cells.
+// Control characters are drawn in caret or code point notation, and tabs as
+// blanks.
+//
+// Padding is drawn with the default style, and g with the style of its first
+// rune.
+func (l *Line) draw(g *glyph, from int) (err error) {
+	if from < g.cell {
+		if err = l.unstyle(); err != nil {
+			return
+		}
+		if err = l.pad(g.cell - from); err != nil {
+			return
+		}
+	}
+	if err = l.style(g.lo); err != nil {
+		return
+	}
+	for i := g.lo; err == nil && i < g.hi; i++ {
+		err = l.put(l.runeAt(i), g.width)
+	}
+	if err != nil {
+		return
+	}
+	return l.advance(g.width)
+}
+
+// style appends the sequence that sets the style of the rune at the given
+// logical position to the output buffer, unless it is already set.
+func (l *Line) style(position int) error {
+	return l.disp.SetStyle(l.ctrl.Out, l.styleAt(position))
+}
+
+// runeAt returns the rune at the given logical position, which refers to the
+// suggestion following the text of l if it is not less than the number of runes
+// in l.
+func (l *Line) runeAt(position int) *utf8.Rune {
+	if n := l.RuneCount(); position >= n {
+		return l.disp.SuggestionAt(position - n)
+	}
+	return l.RuneAt(int(l.head.Get()) + position)
+}
+
+// styleAt returns the style of the rune at the given logical position, which
+// refers to the suggestion following the text of l if it is not less than the
+// number of runes in l.
+func (l *Line) styleAt(position int) ansi.Style {
+	if position >= l.RuneCount() {
+		return display.SuggestionStyle
+	}
+	return l.disp.StyleAt(position)
+}
+
+// unstyle appends the sequence that restores the default style to the output
+// buffer, unless it is already set.
+func (l *Line) unstyle() error {
+	return l.disp.SetStyle(l.ctrl.Out, ansi.Style{})
+}
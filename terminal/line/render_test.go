@@ -0,0 +1,354 @@
+package line
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/ardnew/embedit/config/defaults"
+	"github.com/ardnew/embedit/seq/ansi"
+	"github.com/ardnew/embedit/seq/ascii"
+	"github.com/ardnew/embedit/seq/utf8"
+	"github.com/ardnew/embedit/terminal/display"
+)
+
+func TestLine_Render(t *testing.T) {
+	t.Parallel()
+	// With a display of 10 columns and a prompt of 2 columns, the first row has
+	// 8 columns for text, and each following row has 10.
+	for name, tt := range map[string]struct {
+		caps  display.Capability
+		steps []step
+	}{
+		"insert": {
+			steps: []step{
+				{do: insert("hello"), want: "hello"},
+				{do: moveTo(1), want: "\x1b[4D"},
+				{do: insert("X"), want: "Xello\x1b[4D"},
+				{do: moveTo(0), want: "\b\b"},
+				{do: insert("ab"), want: "ahXello\x1b[6DbhXello\r\r\n\x1b[A\x1b[4C"},
+			},
+		},
+		"delete": {
+			steps: []step{
+				{do: insert("hello"), want: "hello"},
+				{do: moveTo(2), want: "\b\b\b"},
+				{do: erase(1), want: "\bllo\x1b[K\b\b\b"},
+				{do: moveTo(4), want: "\x1b[3C"},
+				{do: erase(3), want: "\b\b\b\x1b[K"},
+			},
+		},
+		"wrap": {
+			steps: []step{
+				{do: insert("abcdefgh"), want: "abcdefgh\r\r\n"},
+				{do: insert("ij"), want: "ij"},
+				{do: moveTo(7), want: "\x1b[A\x1b[7C"},
+				{do: insert("X"), want: "X\r\r\nhij\r"},
+			},
+		},
+		"shrink": {
+			// Rows no longer occupied by the line are erased.
+			steps: []step{
+				{do: insert("abcdefghijklmnopqrst"), want: "abcdefgh\r\r\nijklmnopqr\r\r\nst"},
+				{do: erase(3), want: "\x1b[A\x1b[7C\x1b[J"},
+				{do: erase(10), want: "\x1b[A\x1b[J"},
+				{do: erase(7), want: "\x1b[7D\x1b[K"},
+			},
+		},
+		"history-recall": {
+			// Recalled lines replace the text without first erasing it, so only
+			// the cells that differ are drawn.
+			steps: []step{
+				{do: set("make all"), want: "make all\r\r\n"},
+				{do: set("make test"), want: "\x1b[A\x1b[7Ctes\r\r\nt"},
+				{do: set("make test"), want: ""},
+				{do: set("mv a b"), want: "\x1b[A\x1b[2Cv a b\x1b[J"},
+				{do: set(""), want: "\x1b[6D\x1b[K"},
+			},
+		},
+		"control": {
+			// C0 controls are drawn in caret notation, and C1 controls as their code
+			// point, which wraps like a wide rune.
+			steps: []step{
+				{do: insert("a\x1bb"), want: "a^[b"},
+				{do: insert("\u009b"), want: "    \r\r\n<U+009B>"},
+				{do: insert("c"), want: "c"},
+				{do: erase(2), want: "\x1b[A\b\b\b\x1b[J"},
+			},
+		},
+		"ich-dch": {
+			// Cells following an insertion or deletion are shifted in place.
+			caps: display.CapInsertChar | display.CapDeleteChar,
+			steps: []step{
+				{do: insert("hello"), want: "hello"},
+				{do: moveTo(1), want: "\x1b[4D"},
+				{do: insert("X"), want: "\x1b[@X"},
+				{do: erase(1), want: "\b\x1b[P"},
+				{do: moveTo(0), want: "\b"},
+				{do: insert("ab"), want: "\x1b[@a\x1b[@b"},
+				{do: erase(2), want: "\b\b\x1b[2P"},
+			},
+		},
+		"ich-across-rows": {
+			// Cells are not shifted across rows, so the line is redrawn instead.
+			caps: display.CapInsertChar | display.CapDeleteChar,
+			steps: []step{
+				{do: insert("abcdefghij"), want: "abcdefgh\r\r\nij"},
+				{do: moveTo(0), want: "\x1b[A"},
+				{do: insert("X"), want: "Xabcdefg\r\r\nhij\x1b[A"},
+				{do: erase(1), want: "\babcdefgh\r\r\nij\x1b[K\x1b[A"},
+			},
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			s := newScreen(10, "> ")
+			s.disp.SetCapabilities(tt.caps)
+			s.run(t, tt.steps)
+		})
+	}
+}
+
+func TestLine_RenderRightPrompt(t *testing.T) {
+	t.Parallel()
+	// With a display of 16 columns, a prompt of 2 columns, and a right prompt of
+	// 3 columns, the right prompt is drawn in columns 12–14, and is hidden once
+	// the text reaches column 12.
+	for name, tt := range map[string]struct {
+		steps []step
+	}{
+		"type": {
+			// The right prompt is drawn once, and not again while erasing text.
+			steps: []step{
+				{do: right("[a]"), want: "\x1b[10C[a]\x1b[13D"},
+				{do: insert("ab"), want: "ab"},
+				{do: erase(1), want: "\b \b"},
+				{do: insert("bcdefghi"), want: "bcdefghi"},
+				{do: insert("j"), want: "\x1b[Kj"},
+				{do: erase(1), want: "\b\x1b[K\x1b[C[a]\x1b[4D"},
+			},
+		},
+		"change": {
+			// A right prompt of the same width is drawn over the previous one.
+			steps: []step{
+				{do: right("[a]"), want: "\x1b[10C[a]\x1b[13D"},
+				{do: insert("ab"), want: "ab"},
+				{do: right("[a]"), want: ""},
+				{do: right("[b]"), want: "\x1b[8C[b]\x1b[11D"},
+				{do: right("[ab]"), want: "\x1b[K\x1b[7C[ab]\x1b[11D"},
+				{do: right(""), want: "\x1b[K"},
+				{do: right(""), want: ""},
+			},
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			s := newScreen(16, "> ")
+			s.run(t, tt.steps)
+		})
+	}
+}
+
+// counter is a display.Prompter that generates a prompt containing the number
+// of times it was called.
+type counter struct{ n int }
+
+func (c *counter) Prompt(p []rune) int {
+	c.n++
+	return copy(p, []rune{rune('0' + c.n%10), '>', ' '})
+}
+
+// redraw returns an action that redraws the prompt and line.
+func redraw() func(l *Line) error {
+	return func(l *Line) error { return l.Redraw() }
+}
+
+// prompter returns an action that sets the Prompter of the display.
+func prompter(p display.Prompter) func(l *Line) error {
+	return func(l *Line) error { l.disp.SetPrompter(p); return nil }
+}
+
+// prompt returns an action that sets the static prompt of the display.
+func prompt(p []rune) func(l *Line) error {
+	return func(l *Line) error { l.disp.SetPrompt(p); return nil }
+}
+
+func TestLine_RenderPrompter(t *testing.T) {
+	t.Parallel()
+	for name, tt := range map[string]struct {
+		steps []step
+	}{
+		"each-redraw": {
+			// The Prompter is called each time the prompt is drawn.
+			steps: []step{
+				{do: prompter(&counter{}), want: ""},
+				{do: insert("ab"), want: "ab"},
+				{do: redraw(), want: "\r\x1b[J1> ab"},
+				{do: redraw(), want: "\r\x1b[J2> ab"},
+				{do: insert("c"), want: "c"},
+				{do: redraw(), want: "\r\x1b[J3> abc"},
+			},
+		},
+		"unset": {
+			// Without a Prompter, the last prompt generated is retained, and SetPrompt
+			// with nil restores the default prompt.
+			steps: []step{
+				{do: prompter(&counter{}), want: ""},
+				{do: redraw(), want: "\r\x1b[J1> "},
+				{do: prompter(nil), want: ""},
+				{do: redraw(), want: "\r\x1b[J1> "},
+				{do: prompt(nil), want: ""},
+				{do: redraw(), want: "\r\x1b[J> "},
+			},
+		},
+		"static": {
+			// The static prompt is a copy of the runes given to SetPrompt.
+			steps: func() []step {
+				static := []rune("$ ")
+				return []step{
+					{do: prompt(static), want: ""},
+					{do: func(l *Line) error { static[0] = '#'; return nil }, want: ""},
+					{do: redraw(), want: "\r\x1b[J$ "},
+					{do: prompt(nil), want: ""},
+					{do: redraw(), want: "\r\x1b[J> "},
+				}
+			}(),
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			s := newScreen(10, "> ")
+			s.run(t, tt.steps)
+			// The default prompt is never modified.
+			if diff := cmp.Diff([]rune("> "), defaults.Prompt); diff != "" {
+				t.Errorf("defaults.Prompt mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// highlight is a display.Highlighter that fills the same spans regardless of
+// the text of the line.
+type highlight []display.Span
+
+func (h highlight) Highlight(_ *utf8.Iterable, spans []display.Span) int {
+	return copy(spans, h)
+}
+
+func TestLine_RenderHighlight(t *testing.T) {
+	t.Parallel()
+	bold := ansi.Style{}.Bold()
+	red := ansi.Style{}.Foreground(ansi.Red)
+	for name, tt := range map[string]struct {
+		spans highlight
+		steps []step
+	}{
+		"span": {
+			// The style is reset after the span, and after the output of each step.
+			spans: highlight{{Lo: 0, Hi: 2, Style: bold}},
+			steps: []step{
+				{do: insert("ls"), want: "\x1b[0;1ml\x1b[0m\x1b[0;1ms\x1b[0m"},
+				{do: insert(" -l"), want: " -l"},
+				{do: moveTo(0), want: "\x1b[5D"},
+				{do: insert("x"), want: "\x1b[0;1mxl\x1b[0ms -l\x1b[5D"},
+			},
+		},
+		"adjacent": {
+			// Each span sets its own style without first resetting the style.
+			spans: highlight{{Lo: 0, Hi: 2, Style: bold}, {Lo: 2, Hi: 4, Style: red}},
+			steps: []step{
+				{do: set("abcde"), want: "\x1b[0;1mab\x1b[0;31mcd\x1b[0me"},
+			},
+		},
+		"across-wrap": {
+			// The span continues on the second row without resetting the style.
+			spans: highlight{{Lo: 6, Hi: 10, Style: red}},
+			steps: []step{
+				{do: set("abcdefghijkl"), want: "abcdef\x1b[0;31mgh\r\r\nij\x1b[0mkl"},
+				{do: moveTo(7), want: "\x1b[A\x1b[5C"},
+				{do: erase(2), want: "\b\bh\x1b[0;31mij\r\r\nkl\x1b[0m\x1b[K\x1b[A\x1b[5C"},
+			},
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var spans [4]display.Span
+			s := newScreen(10, "> ")
+			s.disp.SetHighlighter(tt.spans, spans[:])
+			s.run(t, tt.steps)
+		})
+	}
+}
+
+func TestLine_RenderDumb(t *testing.T) {
+	t.Parallel()
+	// With a display of 12 columns and a prompt of 2 columns, 9 columns are
+	// available for text and overflow markers. No escape sequences are written.
+	for name, tt := range map[string]struct {
+		steps []step
+	}{
+		"insert": {
+			steps: []step{
+				{do: insert("hello"), want: "hello"},
+				{do: moveTo(1), want: "\b\b\b\b"},
+				{do: insert("X"), want: "Xello\b\b\b\b"},
+				{do: moveTo(0), want: "\b\b"},
+				{do: insert("ab"), want: "ahXello\b\b\b\b\b\bbhXello\b\b\b\b\b\b"},
+			},
+		},
+		"delete": {
+			steps: []step{
+				// Erased cells are overwritten with blanks.
+				{do: insert("hello"), want: "hello"},
+				{do: moveTo(2), want: "\b\b\b"},
+				{do: erase(1), want: "\bllo \b\b\b\b"},
+				{do: moveTo(4), want: "llo"},
+				{do: erase(3), want: "\b\b\b   \b\b\b"},
+			},
+		},
+		"move": {
+			// The cursor is moved left with BS or CR, and right by reprinting the
+			// runes it passes over.
+			steps: []step{
+				{do: insert("abcdef"), want: "abcdef"},
+				{do: moveTo(4), want: "\b\b"},
+				{do: moveTo(0), want: "\b\b\b\b"},
+				{do: moveTo(3), want: "abc"},
+				{do: moveTo(6), want: "def"},
+			},
+		},
+		"redraw": {
+			steps: []step{
+				// The row is reprinted from its first column.
+				{do: insert("abc"), want: "abc"},
+				{do: moveTo(1), want: "\b\b"},
+				{do: redraw(), want: "\r> abc\b\b"},
+			},
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			s := newScreen(12, "> ")
+			s.disp.SetDumb(true)
+			s.disp.SetHorizontalScroll(true)
+			for i, st := range tt.steps {
+				if err := st.do(&s.line); err != nil {
+					t.Errorf("step %d: unexpected error: %v", i, err)
+				}
+				got := s.drain()
+				if diff := cmp.Diff(st.want, got); len(diff) > 0 {
+					t.Errorf("step %d: diff output (-want +got):%s\n", i, diff)
+				}
+				if strings.ContainsRune(got, ascii.ESC) {
+					t.Errorf("step %d: output contains ESC: %q", i, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package line
+
+import (
+	"github.com/ardnew/embedit/seq/ansi"
+	"github.com/ardnew/embedit/terminal/display"
+)
+
+// Overflow markers drawn at the left and right edges of a horizontally scrolled
+// line, indicating that text exists beyond the visible portion of the line.
+const (
+	scrollMarkLeft  = '<'
+	scrollMarkRight = '>'
+)
+
+// scrollColumns returns the number of columns available for text and overflow
+// markers following the prompt when horizontal scrolling is enabled.
+//
+// The last column of the display is never used, so that terminals which wrap
+// eagerly never advance to the next row.
+func (l *Line) scrollColumns() int {
+	if n := l.disp.Width() - l.disp.PromptWidth() - 1; n > 0 {
+		return n
+	}
+	return 1
+}
+
+// scrollMarks returns the number of columns occupied by the left and right
+// overflow markers when the first visible position is skip.
+func (l *Line) scrollMarks(skip int) (left, right int) {
+	if skip > 0 {
+		left = 1
+	}
+	if left+l.width(skip, -1, l.disp.PromptWidth()+left) >= l.scrollColumns() {
+		right = 1
+	}
+	return
+}
+
+// scrollFits returns true if and only if the cursor at the given position is
+// visible when the first visible position is skip.
+func (l *Line) scrollFits(skip, position int) bool {
+	if position < skip {
+		return false
+	}
+	left, right := l.scrollMarks(skip)
+	col := left + l.width(skip, position-skip, l.disp.PromptWidth()+left)
+	need := 1 // Columns occupied by the glyph under the cursor
+	if position < l.RuneCount() {
+		r := l.RuneAt(int(l.head.Get()) + position)
+		if n := runeWidth(r, l.disp.PromptWidth()+col, l.disp.Width()); n > need {
+			need = n
+		}
+	}
+	return col+need <= l.scrollColumns()-right
+}
+
+// scroll updates the first visible position such that the cursor at the given
+// position is visible, and returns true if and only if it was changed.
+//
+// The visible portion of the line only moves when the cursor would otherwise
+// leave it, or when text has been removed such that more of the line can be
+// shown.
+func (l *Line) scroll(position int) bool {
+	prev := int(l.skip.Get())
+	skip := prev
+	if skip > position {
+		skip = position
+	}
+	for skip < position && !l.scrollFits(skip, position) {
+		skip++
+	}
+	for skip > 0 && l.scrollFits(skip-1, position) {
+		if _, right := l.scrollMarks(skip - 1); right != 0 {
+			break
+		}
+		skip--
+	}
+	l.skip.Set(uint32(skip))
+	return skip != prev
+}
+
+// scrollTo sets the logical cursor position, scrolling the visible portion of
+// the line if necessary, and appends sequences to the output buffer that move
+// the cursor to that position. The cells of the visible portion of the line that
+// changed are drawn if it was scrolled or if redraw is true.
+func (l *Line) scrollTo(position int, redraw bool) (err error) {
+	position = l.setPosition(position)
+	if l.scroll(position) || redraw {
+		if l.disp.Echo() {
+			err = l.scrollDraw()
+		}
+	} else if l.disp.Echo() {
+		err = l.scrollCursor(l.scrollColumn(position))
+	}
+	if l.flush {
+		l.ctrl.Flush()
+	}
+	return
+}
+
+// scrollColumn returns the column, relative to the end of the prompt, at which
+// the rune at the given visible position is drawn.
+func (l *Line) scrollColumn(position int) int {
+	skip := int(l.skip.Get())
+	left, _ := l.scrollMarks(skip)
+	return left + l.width(skip, position-skip, l.disp.PromptWidth()+left)
+}
+
+// scrollCursor appends sequences to the output buffer that move the cursor to
+// the given column, relative to the end of the prompt, and updates the cursor's
+// X coordinate.
+//
+// Dumb terminals move the cursor right by reprinting the glyphs it passes over.
+func (l *Line) scrollCursor(col int) (err error) {
+	x := l.disp.PromptWidth() + col
+	if l.disp.Dumb() && x > l.curs.X() {
+		return l.scrollReprint(x)
+	}
+	return l.curs.MoveTo(x, l.curs.Y())
+}
+
+// scrollReprint appends the visible glyphs from the cursor up to the given X
+// coordinate to the output buffer, moving the cursor right without any control
+// sequences, and updates the cursor's X coordinate.
+func (l *Line) scrollReprint(x int) (err error) {
+	skip := int(l.skip.Get())
+	left, _ := l.scrollMarks(skip)
+	from := l.curs.X()
+	col := l.disp.PromptWidth()
+	if left != 0 {
+		if col == from {
+			if err = l.ctrl.Out.WriteByte(scrollMarkLeft); err != nil {
+				return
+			}
+		}
+		col++
+	}
+	h, end := int(l.head.Get()), l.RuneCount()
+	for i := skip; i < end && col < x; i++ {
+		r := l.RuneAt(h + i)
+		n := runeWidth(r, col, l.disp.Width())
+		// Zero-width runes at the cursor belong to the glyph preceding it.
+		if col > from || col == from && n > 0 {
+			if err = l.style(i); err != nil {
+				return
+			}
+			if err = l.put(r, n); err != nil {
+				return
+			}
+		}
+		col += n
+	}
+	if err = l.unstyle(); err != nil {
+		return
+	}
+	_, _ = l.curs.Set(col, l.curs.Y())
+	if l.flush {
+		l.ctrl.Flush()
+	}
+	return
+}
+
+// scrollLayout iterates over the cells of the visible portion of a horizontally
+// scrolled Line in the order they are drawn: the left overflow marker, the
+// visible glyphs, blank cells up to the right overflow marker, and the right
+// overflow marker. Cells are indexed by their column relative to the end of
+// the prompt, as in the display's model.
+type scrollLayout struct {
+	l     *Line
+	g     glyph // Contains no runes (g.lo == g.hi) for markers and blanks
+	mark  byte  // Byte drawn in g if it contains no runes
+	left  int   // Number of cells occupied by the left overflow marker
+	right int   // Number of cells occupied by the right overflow marker
+	last  int   // Cell following the last visible glyph
+	end   int   // Number of runes in l, or the first rune not visible
+}
+
+// reset prepares it to iterate over the visible cells of l.
+func (it *scrollLayout) reset(l *Line) *scrollLayout {
+	skip := int(l.skip.Get())
+	left, right := l.scrollMarks(skip)
+	*it = scrollLayout{
+		l:     l,
+		left:  left,
+		right: right,
+		last:  l.scrollColumns() - right,
+		end:   l.RuneCount(),
+	}
+	it.g.lo, it.g.hi = skip, skip
+	return it
+}
+
+// next advances it to the next cell or glyph, and returns false if and only if
+// there are none remaining.
+func (it *scrollLayout) next() bool {
+	g := &it.g
+	g.cell, g.lo, g.pad, g.width = g.end(), g.hi, 0, 1
+	switch {
+	case g.cell < it.left:
+		it.mark, g.val = scrollMarkLeft, display.MakeCell(scrollMarkLeft)
+		return true
+	case it.glyph():
+		return true
+	case g.cell < it.last:
+		it.mark, g.val = ansi.Space, display.CellBlank
+		return true
+	case g.cell == it.last && it.right != 0:
+		it.mark, g.val = scrollMarkRight, display.MakeCell(scrollMarkRight)
+		return true
+	}
+	return false
+}
+
+// glyph advances it to the next visible glyph of text, and returns false if and
+// only if no more text is visible. All zero-width runes following a glyph are
+// combined with it, as in the wrapped layout.
+func (it *scrollLayout) glyph() bool {
+	g := &it.g
+	l := it.l
+	x := l.disp.PromptWidth() + g.cell
+	g.width, g.val = 0, display.CellBlank
+	for g.hi < it.end && g.width == 0 {
+		r := l.RuneAt(int(l.head.Get()) + g.hi)
+		if g.width = runeWidth(r, x, l.disp.Width()); g.hi == g.lo {
+			g.val = display.MakeCell(rune(*r))
+		} else {
+			g.val = g.val.Combine(rune(*r))
+		}
+		g.hi++
+	}
+	if g.width == 0 || g.cell+g.width > it.last {
+		// The glyph does not fit; no more text is visible.
+		g.hi, g.width, it.end = g.lo, 1, g.lo
+		return false
+	}
+	for g.hi < it.end {
+		r := l.RuneAt(int(l.head.Get()) + g.hi)
+		if runeWidth(r, x, l.disp.Width()) != 0 {
+			break
+		}
+		g.val = g.val.Combine(rune(*r))
+		g.hi++
+	}
+	if s := l.styleAt(g.lo); !s.IsDefault() {
+		g.val = g.val.Combine(rune(s.Fg)).Combine(rune(s.Bg)).Combine(rune(s.Attr))
+	}
+	return true
+}
+
+// scrollDraw appends the cells of the visible portion of the line, including
+// any overflow markers, that differ from the display's model to the output
+// buffer, and then moves the cursor to the logical cursor position.
+//
+// Cells beyond those of the model are assumed blank, as in the wrapped layout,
+// so the blanks following the text are only drawn to erase glyphs from before.
+func (l *Line) scrollDraw() (err error) {
+	// No suggestion is drawn while scrolling horizontally.
+	l.disp.ClearSuggestion()
+	l.disp.Highlight(l.iter.Reset())
+	l.disp.ResetStyle()
+	m := l.disp.Model()
+	// Find the range of cells that differ from the model.
+	lo, hi, size := -1, -1, 0
+	var it scrollLayout
+	for it.reset(l); it.next(); {
+		g := &it.g
+		for k := g.cell; k < g.end(); k++ {
+			if c := g.at(k); c != m.At(k) && (c != display.CellBlank || k < m.Len()) {
+				if lo < 0 {
+					lo = g.cell
+				}
+				hi = g.end()
+			}
+		}
+		size = g.end()
+	}
+	if lo >= 0 {
+		if err = l.scrollCursor(lo); err != nil {
+			return
+		}
+		for it.reset(l); err == nil && it.next(); {
+			if g := &it.g; lo <= g.cell && g.cell < hi {
+				err = l.scrollPut(&it)
+			}
+		}
+		if err != nil {
+			return
+		}
+		if err = l.unstyle(); err != nil {
+			return
+		}
+	}
+	for it.reset(l); it.next(); {
+		for k := it.g.cell; k < it.g.end(); k++ {
+			m.Set(k, it.g.at(k))
+		}
+	}
+	m.SetLen(size)
+	return l.scrollCursor(l.scrollColumn(l.Position()))
+}
+
+// scrollPut appends the current cell or glyph of it to the output buffer, and
+// advances the cursor's X coordinate accordingly.
+func (l *Line) scrollPut(it *scrollLayout) (err error) {
+	if it.g.hi > it.g.lo {
+		return l.draw(&it.g, it.g.cell)
+	}
+	if err = l.unstyle(); err != nil {
+		return
+	}
+	if err = l.ctrl.Out.WriteByte(it.mark); err != nil {
+		return
+	}
+	return l.advance(1)
+}
+
+// scrollPositionAt returns the logical cursor position in the text of l that is
+// nearest to the given X coordinate when horizontal scrolling is enabled.
+func (l *Line) scrollPositionAt(x int) int {
+	skip := int(l.skip.Get())
+	left, _ := l.scrollMarks(skip)
+	col := l.disp.PromptWidth() + left
+	h, end := int(l.head.Get()), l.RuneCount()
+	for i := skip; i < end; i++ {
+		if col += runeWidth(l.RuneAt(h+i), col, l.disp.Width()); x < col {
+			return i
+		}
+	}
+	return end
+}
@@ -0,0 +1,62 @@
+package line
+
+import "testing"
+
+func TestLine_Scroll(t *testing.T) {
+	t.Parallel()
+	// With a display of 12 columns and a prompt of 2 columns, 9 columns are
+	// available for text and overflow markers.
+	for name, tt := range map[string]struct {
+		dumb  bool
+		steps []step
+	}{
+		"type": {
+			steps: []step{
+				{do: insert("abcdefgh"), want: "abcdefgh"},
+				{do: insert("i"), want: "\x1b[8D<cdefghi"},
+				{do: insert("j"), want: "\x1b[7Ddefghij"},
+				{do: moveTo(9), want: "\b"},
+				{do: moveTo(10), want: "\x1b[C"},
+			},
+		},
+		"no-scroll": {
+			// Only the changed cells are drawn while the view does not scroll.
+			steps: []step{
+				{do: insert("abcd"), want: "abcd"},
+				{do: moveTo(1), want: "\b\b\b"},
+				{do: insert("X"), want: "Xbcd\b\b\b"},
+				{do: erase(1), want: "\bbcd \x1b[4D"},
+				{do: moveTo(4), want: "\x1b[3C"},
+				{do: set("abcd"), want: ""},
+				{do: set("abce"), want: "\be"},
+			},
+		},
+		"scroll-back": {
+			steps: []step{
+				{do: insert("abcdefghijkl"), want: "abcdefgh\x1b[8D<cdefghi\x1b[7Ddefghij\x1b[7Defghijk\x1b[7Dfghijkl"},
+				{do: moveTo(0), want: "\x1b[8Dabcdefgh>\x1b[9D"},
+				{do: moveTo(12), want: "<fghijkl \b"},
+				{do: erase(1), want: "\x1b[7Defghijk"},
+				{do: set("ab"), want: "\x1b[8Dab      \x1b[6D"},
+			},
+		},
+		"dumb": {
+			dumb: true,
+			steps: []step{
+				{do: insert("abcdefghi"), want: "abcdefgh\b\b\b\b\b\b\b\b<cdefghi"},
+				{do: moveTo(0), want: "\b\b\b\b\b\b\b\babcdefgh>\b\b\b\b\b\b\b\b\b"},
+				{do: moveTo(2), want: "ab"},
+				{do: insert("X"), want: "Xcdefg\b\b\b\b\b"},
+			},
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			s := newScreen(12, "> ")
+			s.disp.SetHorizontalScroll(true)
+			s.disp.SetDumb(tt.dumb)
+			s.run(t, tt.steps)
+		})
+	}
+}
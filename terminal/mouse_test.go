@@ -0,0 +1,60 @@
+package terminal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTerminal_ReadLineMouse(t *testing.T) {
+	t.Parallel()
+	type want struct {
+		line    string
+		request bool // The cursor position was requested
+	}
+	for name, tt := range map[string]struct {
+		input []string
+		want  want
+	}{
+		"origin-reported": {
+			// The line starts on row 10, so a click in column 4 selects position 1.
+			input: []string{"hello", "\x1b[10;8R", "\x1b[<0;4;10M\x1b[<0;4;10m", "X\r"},
+			want:  want{line: "hXello", request: true},
+		},
+		"origin-reported-wrapped": {
+			input: []string{"abcdefghij", "\x1b[5;3R", "\x1b[<0;2;6M", "X\r"},
+			want:  want{line: "abcdefghiXj", request: true},
+		},
+		"origin-assumed": {
+			// Without a reply, the line is assumed to be on the last row.
+			input: []string{"hello", "\x1b[<0;5;24M", "X\r"},
+			want:  want{line: "heXllo", request: true},
+		},
+		"click-above-line": {
+			input: []string{"hello", "\x1b[10;8R", "\x1b[<0;4;9M", "X\r"},
+			want:  want{line: "helloX", request: true},
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var term Terminal
+			var rec recorder
+			dev := &device{input: tt.input}
+			term.Configure(dev, []rune("> "), 10, 24, false)
+			term.EnableMouse(true)
+			term.SetKeyHandler(&rec)
+			if err := term.ReadLine(); err != nil {
+				t.Fatalf("ReadLine() error = %v", err)
+			}
+			got := want{request: strings.Contains(dev.String(), "\x1b[6n")}
+			if len(rec.lines) > 0 {
+				got.line = rec.lines[0]
+			}
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("ReadLine() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package terminal
+
+import (
+	"io"
+
+	"github.com/ardnew/embedit/config/limits"
+)
+
+// output implements wire.Writer for the output buffer of a Terminal.
+//
+// All writes to the output buffer are routed through output so that software
+// flow control can apply its Policy when the buffer fills while Paused.
+type output struct {
+	t *Terminal
+}
+
+// Write appends the bytes in p to the output buffer.
+func (o *output) Write(p []byte) (n int, err error) {
+	if err = o.t.reserve(len(p)); err != nil {
+		return
+	}
+	return o.t.out.Write(p)
+}
+
+// WriteByte appends b to the output buffer.
+func (o *output) WriteByte(b byte) error {
+	if err := o.t.reserve(1); err != nil {
+		return err
+	}
+	return o.t.out.WriteByte(b)
+}
+
+// ReadFrom copies the bytes from r to the output buffer.
+//
+// The output buffer is used to copy the encoding of individual runes, so
+// ReadFrom reserves space for the widest rune.
+func (o *output) ReadFrom(r io.Reader) (n int64, err error) {
+	if err = o.t.reserve(limits.MaxBytesPerRune); err != nil {
+		return
+	}
+	return o.t.out.ReadFrom(r)
+}
+
+// WriteEOL appends the output end-of-line sequence to the output buffer.
+func (o *output) WriteEOL() (n int, err error) {
+	if err = o.t.reserve(limits.MaxBytesPerRune); err != nil {
+		return
+	}
+	return o.t.out.WriteEOL()
+}
+
+// Reset discards all bytes in the output buffer.
+func (o *output) Reset() {
+	o.t.out.Reset()
+}
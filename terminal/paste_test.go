@@ -0,0 +1,110 @@
+package terminal
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/ardnew/embedit/config/limits"
+	"github.com/ardnew/embedit/terminal/clipboard/paste"
+	"github.com/ardnew/embedit/terminal/key"
+)
+
+// snapshot is a KeyHandler that records the text of the line as each event is
+// received, before the event is applied.
+type snapshot struct {
+	text string
+}
+
+func (s *snapshot) HandleEvent(t *Terminal, ev *key.Event) (handled, eol bool, err error) {
+	s.text = text(t)
+	return
+}
+
+func TestTerminal_ReadLinePaste(t *testing.T) {
+	t.Parallel()
+	long := strings.Repeat("x", limits.RunesPerLine)
+	type want struct {
+		lines  []string
+		output string // Output with bracketed paste disabled, if non-empty
+	}
+	for name, tt := range map[string]struct {
+		input  []string
+		policy paste.Policy
+		want   want
+	}{
+		"type-ahead": {
+			input: []string{"ab\rcd\r"},
+			want: want{
+				lines:  []string{"ab", "cd"},
+				output: "> ab\r\r\n> cd\r\r\n> \r\r\n",
+			},
+		},
+		"type-ahead-chunks": {
+			input: []string{"ab\rc", "d\re", "f\r"},
+			want:  want{lines: []string{"ab", "cd", "ef"}},
+		},
+		"split": {
+			input:  []string{"\x1b[200~x\ny\x1b[201~\r"},
+			policy: paste.Policy{Newline: paste.Split},
+			want:   want{lines: []string{"x", "y"}},
+		},
+		"split-typed": {
+			input:  []string{"a\x1b[200~x\ny\x1b[201~b\r"},
+			policy: paste.Policy{Newline: paste.Split},
+			want:   want{lines: []string{"ax", "yb"}},
+		},
+		"join": {
+			input:  []string{"\x1b[200~x\ny\x1b[201~\r"},
+			policy: paste.Policy{Newline: paste.Join},
+			want:   want{lines: []string{"x y"}},
+		},
+		"reject": {
+			input:  []string{"a\x1b[200~x\ny\x1b[201~b\r"},
+			policy: paste.Policy{Newline: paste.Reject},
+			want:   want{lines: []string{"ab"}},
+		},
+		"truncate": {
+			input:  []string{"\x1b[200~" + long + "yz\x1b[201~\r"},
+			policy: paste.Policy{Overflow: paste.Truncate},
+			want:   want{lines: []string{long}},
+		},
+		"wrap": {
+			input:  []string{"\x1b[200~" + long + "yz\x1b[201~\r"},
+			policy: paste.Policy{Overflow: paste.Wrap},
+			want:   want{lines: []string{long, "yz"}},
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var term Terminal
+			var snap snapshot
+			// Ctrl+D on an empty line ends the input.
+			dev := &device{input: append(tt.input, "\x04")}
+			term.Configure(dev, []rune("> "), 1000, 24, false)
+			term.EnableBracketedPaste(tt.want.output == "")
+			term.SetPastePolicy(tt.policy)
+			term.SetKeyHandler(&snap)
+			var got want
+			for len(got.lines) <= len(tt.want.lines) {
+				err := term.ReadLine()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("ReadLine() error = %v", err)
+				}
+				got.lines = append(got.lines, snap.text)
+			}
+			if tt.want.output != "" {
+				got.output = dev.String()
+			}
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("ReadLine() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package terminal
+
+import (
+	"time"
+
+	"github.com/ardnew/embedit/errors"
+	"github.com/ardnew/embedit/seq/ansi"
+	"github.com/ardnew/embedit/terminal/key"
+)
+
+// query identifies the purpose of a pending device status report (DSR) query,
+// which determines how the cursor position report received in reply is applied.
+type query uint8
+
+// deadliner is implemented by input devices whose reads can be interrupted at a
+// deadline, e.g., *os.File, net.Conn, or sys.SizeWatcher.
+type deadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
+// Constant values of enumerated type query.
+const (
+	queryNone     query = iota // No query pending
+	querySize                  // Display size, see ProbeSize
+	queryPosition              // Cursor position, see Resync
+	queryOrigin                // Screen row of the line, see EnableMouse
+)
+
+// ProbeSize discovers the size of the display by moving the cursor to the
+// bottom-right corner of the display and requesting a cursor position report,
+// which contains the row and column of that corner. The cursor is then restored
+// to its original position.
+//
+// If the terminal replies within the given timeout, the display size is updated.
+// Otherwise, the display size is unchanged, and ErrTimeout is returned. A reply
+// received after the timeout, while reading a line, still updates the display
+// size.
+//
+// Keys received before the reply are retained for reading. ProbeSize returns the
+// resulting display size.
+//
+// The timeout can only be enforced if reading from the input device returns when
+// no input is available, or if the input device implements SetReadDeadline, as
+// do *os.File, net.Conn, and sys.SizeWatcher. If SetReadDeadline returns an
+// error, the reply is not awaited, and ErrTimeout is returned immediately.
+//
+// Dumb terminals are never queried, and ErrTimeout is returned immediately.
+func (t *Terminal) ProbeSize(timeout time.Duration) (width, height int, err error) {
+	if t.display.Dumb() {
+		width, height = t.display.Size()
+		return width, height, &errors.ErrTimeout
+	}
+	_, _ = t.output.Write(ansi.SCP)
+	_, _ = t.output.Write(ansi.FAR)
+	_, _ = t.output.Write(ansi.DSR)
+	_, _ = t.output.Write(ansi.RCP)
+	t.query = querySize
+	if _, err = t.Flush(); err == nil {
+		if ev, ok := t.await(timeout); ok {
+			t.report(&ev)
+		} else {
+			err = &errors.ErrTimeout
+		}
+	}
+	width, height = t.display.Size()
+	return
+}
+
+// Resync requests the position of the cursor from the terminal, reconciles the
+// Cursor's X, Y coordinates with the reported position, and redraws the prompt
+// and the current line.
+//
+// The Cursor's coordinates are otherwise maintained only by dead reckoning from
+// the output written by the Terminal. Resync recovers from output written by
+// other sources, e.g., log messages or a remote echo, which moved the cursor or
+// overwrote the line. If the cursor is not in the column expected, the line is
+// drawn again starting on the row following the cursor.
+//
+// If the terminal does not reply within the given timeout, the display is
+// unchanged, and ErrTimeout is returned. A reply received after the timeout,
+// while reading a line, is still applied.
+//
+// The timeout is enforced as with ProbeSize.
+//
+// Dumb terminals are never queried, and ErrTimeout is returned immediately.
+func (t *Terminal) Resync(timeout time.Duration) (err error) {
+	if t.display.Dumb() {
+		return &errors.ErrTimeout
+	}
+	t.requestPosition()
+	if _, err = t.Flush(); err == nil {
+		if ev, ok := t.await(timeout); ok {
+			t.report(&ev)
+			_, _ = t.Flush()
+		} else {
+			err = &errors.ErrTimeout
+		}
+	}
+	return
+}
+
+// requestPosition appends a request for a cursor position report to the output
+// buffer. The report is applied by Resync when received.
+func (t *Terminal) requestPosition() {
+	_, _ = t.output.Write(ansi.DSR)
+	t.query = queryPosition
+}
+
+// requestOrigin appends a request for a cursor position report to the output
+// buffer, from which the screen row of the first row of the line is set when
+// the report is received.
+func (t *Terminal) requestOrigin() {
+	_, _ = t.output.Write(ansi.DSR)
+	t.query = queryOrigin
+	t.row = t.cursor.Y()
+}
+
+// await reads from the input device until a cursor position report is received
+// or the given timeout expires, and returns the report, if any.
+func (t *Terminal) await(timeout time.Duration) (ev key.Event, ok bool) {
+	ok = t.wait(timeout, func() bool {
+		ev, ok = t.in.Extract(key.CursorPosition)
+		return ok
+	})
+	return
+}
+
+// wait reads from the input device until done returns true or the given timeout
+// expires, and returns the last result of done.
+//
+// If the input device implements deadliner, its read deadline is set to the
+// timeout, and cleared before returning. Otherwise, reading from the input
+// device is assumed to return when no input is available.
+func (t *Terminal) wait(timeout time.Duration, done func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	if d, is := t.rw.(deadliner); is {
+		if d.SetReadDeadline(deadline) != nil {
+			return done()
+		}
+		defer func() { _ = d.SetReadDeadline(time.Time{}) }()
+	}
+	for !done() {
+		if !time.Now().Before(deadline) {
+			return false
+		}
+		if _, err := t.Swell(); err != nil {
+			return done()
+		}
+	}
+	return true
+}
+
+// report applies the given cursor position report according to the pending
+// query. Reports received with no query pending are ignored.
+func (t *Terminal) report(ev *key.Event) {
+	switch t.query {
+	case querySize:
+		if ev.X > 0 && ev.Y > 0 {
+			_ = t.Resize(int(ev.X), int(ev.Y))
+		}
+	case queryPosition:
+		if ev.X > 0 && ev.Y > 0 {
+			t.resync(int(ev.X)-1, int(ev.Y))
+		}
+	case queryOrigin:
+		if ev.Y > 0 {
+			t.cursor.SetOrigin(int(ev.Y) - t.row)
+		}
+	}
+	t.query = queryNone
+}
+
+// resync reconciles the Cursor with the cursor position reported in the given
+// column x (0-based) and screen row (1-based), and redraws the current line.
+func (t *Terminal) resync(x, row int) {
+	c, l := &t.cursor, t.Line()
+	if x != c.X() {
+		// The cursor was moved by output not written by the Terminal, so the line
+		// is drawn again from the start of the next row, leaving that output intact.
+		c.Reset()
+		if x > 0 {
+			_, _ = t.output.WriteEOL()
+			if row < t.display.Height() {
+				row++
+			}
+		}
+		c.SetOrigin(row)
+		_ = l.ShowPrompt()
+		return
+	}
+	// The cursor cannot be below the reported row, since the rows of the line
+	// above the first row of the display have scrolled out of view.
+	if y := c.Y(); y >= row {
+		_, _ = c.Set(x, row-1)
+	}
+	c.SetOrigin(row - c.Y())
+	_ = l.Redraw()
+}
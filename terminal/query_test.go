@@ -0,0 +1,139 @@
+package terminal
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	errs "github.com/ardnew/embedit/errors"
+)
+
+// device is an input/output device whose reads are controlled by the test.
+type device struct {
+	strings.Builder
+	input []string      // Input returned by successive reads
+	block bool          // Reads without a deadline never return
+	dead  time.Time     // Deadline set with SetReadDeadline
+	fail  error         // Error returned by SetReadDeadline
+	never chan struct{} // Never closed
+}
+
+func (d *device) Read(p []byte) (n int, err error) {
+	if len(d.input) > 0 {
+		n = copy(p, d.input[0])
+		if d.input[0] = d.input[0][n:]; d.input[0] == "" {
+			d.input = d.input[1:]
+		}
+		return
+	}
+	switch {
+	case !d.dead.IsZero():
+		time.Sleep(time.Until(d.dead))
+		return 0, os.ErrDeadlineExceeded
+	case d.block:
+		<-d.never
+	}
+	return 0, nil
+}
+
+// deadlineDevice is a device that implements deadliner.
+type deadlineDevice struct{ device }
+
+func (d *deadlineDevice) SetReadDeadline(t time.Time) error {
+	if d.fail != nil {
+		return d.fail
+	}
+	d.dead = t
+	return nil
+}
+
+func TestTerminal_ProbeSize(t *testing.T) {
+	t.Parallel()
+	const timeout = 20 * time.Millisecond
+	type want struct {
+		width, height int
+		timeout       bool
+	}
+	for name, tt := range map[string]struct {
+		rw   io.ReadWriter
+		want want
+	}{
+		"reply": {
+			rw:   &device{input: []string{"\x1b[24;132R"}},
+			want: want{width: 132, height: 24},
+		},
+		"reply-after-keys": {
+			rw:   &device{input: []string{"ab\x1b[50;100R"}},
+			want: want{width: 100, height: 50},
+		},
+		"no-reply-polled": {
+			rw:   &device{},
+			want: want{width: 80, height: 24, timeout: true},
+		},
+		"no-reply-deadline": {
+			rw:   &deadlineDevice{device{block: true}},
+			want: want{width: 80, height: 24, timeout: true},
+		},
+		"no-reply-no-deadline": {
+			rw:   &deadlineDevice{device{block: true, fail: errors.New("no deadline")}},
+			want: want{width: 80, height: 24, timeout: true},
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var term Terminal
+			term.Configure(tt.rw, []rune("> "), 80, 24, false)
+			done := make(chan want, 1)
+			go func() {
+				var got want
+				var err error
+				got.width, got.height, err = term.ProbeSize(timeout)
+				got.timeout = err == &errs.ErrTimeout
+				done <- got
+			}()
+			select {
+			case got := <-done:
+				if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(want{})); diff != "" {
+					t.Errorf("ProbeSize() mismatch (-want +got):\n%s", diff)
+				}
+			case <-time.After(50 * timeout):
+				t.Fatal("ProbeSize() did not return after timeout")
+			}
+		})
+	}
+}
+
+func TestTerminal_Resync(t *testing.T) {
+	t.Parallel()
+	const timeout = 20 * time.Millisecond
+	for name, tt := range map[string]struct {
+		rw io.ReadWriter
+	}{
+		"no-reply-polled":      {rw: &device{}},
+		"no-reply-deadline":    {rw: &deadlineDevice{device{block: true}}},
+		"no-reply-no-deadline": {rw: &deadlineDevice{device{block: true, fail: errors.New("no deadline")}}},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var term Terminal
+			term.Configure(tt.rw, []rune("> "), 80, 24, false)
+			done := make(chan error, 1)
+			go func() { done <- term.Resync(timeout) }()
+			select {
+			case err := <-done:
+				if err != &errs.ErrTimeout {
+					t.Errorf("Resync() error = %v, want %v", err, &errs.ErrTimeout)
+				}
+			case <-time.After(50 * timeout):
+				t.Fatal("Resync() did not return after timeout")
+			}
+		})
+	}
+}
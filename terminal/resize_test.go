@@ -0,0 +1,86 @@
+package terminal
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/ardnew/embedit/terminal/key"
+)
+
+func TestTerminal_Resize(t *testing.T) {
+	t.Parallel()
+	type want struct {
+		output string
+		x, y   int // Cursor position after the resize
+	}
+	for name, tt := range map[string]struct {
+		text  string
+		pos   int // Cursor position in text before the resize
+		width int
+		want  want
+	}{
+		"same-size": {
+			text:  "abcdefghijkl",
+			pos:   12,
+			width: 10,
+			want:  want{output: "", x: 4, y: 1},
+		},
+		"grow": {
+			// The wrapped line is redrawn on one row.
+			text:  "abcdefghijkl",
+			pos:   12,
+			width: 20,
+			want:  want{output: "\r\x1b[A\x1b[J> abcdefghijkl", x: 14, y: 0},
+		},
+		"shrink": {
+			// The line fills the first row, so the cursor is on the second row.
+			text:  "abcdefgh",
+			pos:   8,
+			width: 6,
+			want:  want{output: "\r\x1b[A\x1b[J> abcd\r\r\nefgh", x: 4, y: 1},
+		},
+		"shrink-cursor-inside": {
+			// The line is redrawn on three rows, and the cursor is moved back to the
+			// start of the second row.
+			text:  "abcdefghijkl",
+			pos:   3,
+			width: 5,
+			want:  want{output: "\r\x1b[J> abc\r\r\ndefgh\r\r\nijkl\r\x1b[A", x: 0, y: 1},
+		},
+		"grow-cursor-inside": {
+			text:  "abcdefghijkl",
+			pos:   9,
+			width: 40,
+			want:  want{output: "\r\x1b[A\x1b[J> abcdefghijkl\b\b\b", x: 11, y: 0},
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var term Terminal
+			dev := &device{}
+			term.Configure(dev, []rune("> "), 10, 24, false)
+			term.display.EnablePrompt(true)
+			l := term.Line()
+			if err := l.ShowPrompt(); err != nil {
+				t.Fatalf("ShowPrompt() error = %v", err)
+			}
+			for _, r := range tt.text {
+				_, _ = term.HandleKey(r)
+			}
+			for i := len(tt.text); i > tt.pos; i-- {
+				_, _ = term.HandleKey(key.Left)
+			}
+			_, _ = term.Flush()
+			dev.Reset()
+			if err := term.Resize(tt.width, 24); err != nil {
+				t.Fatalf("Resize() error = %v", err)
+			}
+			got := want{output: dev.String(), x: term.cursor.X(), y: term.cursor.Y()}
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("Resize() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+//go:build history
+// +build history
+
+package terminal
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/ardnew/embedit/terminal/key"
+)
+
+func TestTerminal_SuggestionHistory(t *testing.T) {
+	t.Parallel()
+	history := []string{"make all", "ls -l", "make test"}
+	for name, tt := range map[string]struct {
+		text string
+		want string
+	}{
+		"most-recent":  {text: "ma", want: "ke test"},
+		"older":        {text: "make a", want: "ll"},
+		"single-match": {text: "l", want: "s -l"},
+		"entire-line":  {text: "ls -l", want: ""}, // Nothing follows the text
+		"no-match":     {text: "x", want: ""},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var term Terminal
+			term.Configure(&device{}, []rune("> "), 80, 24, false)
+			term.EnableSuggestions(true)
+			for _, s := range history {
+				for _, r := range s {
+					_, _ = term.HandleKey(r)
+				}
+				_, _ = term.HandleKey(key.Enter)
+			}
+			for _, r := range tt.text {
+				_, _ = term.HandleKey(r)
+			}
+			got := string(term.display.Suggestion())
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Suggestion() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
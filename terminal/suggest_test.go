@@ -0,0 +1,107 @@
+package terminal
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/ardnew/embedit/seq/utf8"
+	"github.com/ardnew/embedit/terminal/key"
+)
+
+// completion is a display.Suggester that suggests the remaining runes of a
+// fixed string to any line that is a prefix of it.
+type completion string
+
+func (c completion) Suggest(text *utf8.Iterable, p []rune) (n int) {
+	s := []rune(string(c))
+	count := text.Count()
+	if count >= len(s) {
+		return 0
+	}
+	for i, r := 0, text.Reset(); i < count; i++ {
+		if rune(*r.Next()) != s[i] {
+			return 0
+		}
+	}
+	return copy(p, s[count:])
+}
+
+func TestTerminal_Suggestion(t *testing.T) {
+	t.Parallel()
+	type want struct {
+		text       string
+		suggestion string
+		output     string // Output of the last key, if non-empty
+	}
+	for name, tt := range map[string]struct {
+		keys []rune
+		want want
+	}{
+		"suggest": {
+			// The suggestion is drawn dimmed after the line.
+			keys: []rune("g"),
+			want: want{text: "g", suggestion: "it commit -a", output: "g\x1b[0;2mit commit -a\x1b[0m\r\x1b[C"},
+		},
+		"accept-right": {
+			keys: append([]rune("gi"), key.Right),
+			want: want{text: "git commit -a"},
+		},
+		"accept-end": {
+			keys: append([]rune("gi"), key.End),
+			want: want{text: "git commit -a"},
+		},
+		"accept-word": {
+			keys: append([]rune("gi"), key.AltRight),
+			// Accepted runes are drawn over the suggestion with the default style.
+			want: want{text: "git", suggestion: " commit -a", output: "t"},
+		},
+		"accept-words": {
+			keys: append([]rune("gi"), key.AltRight, key.AltRight),
+			want: want{text: "git commit", suggestion: " -a"},
+		},
+		"right-inside-line": {
+			// Right only moves the cursor if it is not at end of line.
+			keys: append([]rune("gi"), key.Left, key.Right),
+			want: want{text: "gi", suggestion: "t commit -a"},
+		},
+		"mismatch": {
+			keys: []rune("gx"),
+			want: want{text: "gx"},
+		},
+		"dismiss": {
+			// The suggestion is erased before the line is entered.
+			keys: append([]rune("gi"), key.Enter),
+			want: want{output: "\x1b[K\r\r\n"},
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var term Terminal
+			dev := &device{}
+			term.Configure(dev, []rune("> "), 80, 24, false)
+			term.SetSuggester(completion("git commit -a"))
+			for i, k := range tt.keys {
+				if i == len(tt.keys)-1 {
+					_, _ = term.Flush()
+					dev.Reset()
+				}
+				if _, err := term.HandleKey(k); err != nil {
+					t.Fatalf("HandleKey(%q) error = %v", k, err)
+				}
+			}
+			_, _ = term.Flush()
+			got := want{
+				text:       text(&term),
+				suggestion: string(term.display.Suggestion()),
+			}
+			if tt.want.output != "" {
+				got.output = dev.String()
+			}
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("HandleKey() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -2,14 +2,20 @@ package terminal
 
 import (
 	"io"
+	"time"
+	"unicode/utf8"
 
+	"github.com/ardnew/embedit/config/defaults"
 	"github.com/ardnew/embedit/config/limits"
 	"github.com/ardnew/embedit/errors"
 	"github.com/ardnew/embedit/seq"
+	"github.com/ardnew/embedit/seq/ansi"
+	"github.com/ardnew/embedit/seq/ascii"
 	"github.com/ardnew/embedit/seq/eol"
 	"github.com/ardnew/embedit/terminal/clipboard/paste"
 	"github.com/ardnew/embedit/terminal/cursor"
 	"github.com/ardnew/embedit/terminal/display"
+	"github.com/ardnew/embedit/terminal/flow"
 	"github.com/ardnew/embedit/terminal/history"
 	"github.com/ardnew/embedit/terminal/key"
 	"github.com/ardnew/embedit/terminal/line"
@@ -27,27 +33,57 @@ type Terminal struct {
 	display display.Display
 	history history.History
 
-	in  seq.Buffer
-	out seq.Buffer
+	in     seq.Buffer
+	out    seq.Buffer
+	output output
 
 	paste paste.State
+	clip  paste.Policy
+	brkt  bool // Bracketed paste enabled
+	from  int  // Line position at start of paste
+	full  bool // Pasted text has overflowed the line
+	carry rune // Pasted rune to insert at the start of the next line
+	flow  flow.State
+	xon   bool // Software flow control enabled
+	block flow.Policy
+	hold  time.Duration // Maximum time output is blocked with flow.Block
+	mouse bool
+	query query // Pending query awaiting a cursor position report
+	row   int   // Cursor's Y coordinate when the pending query was sent
+	sync  bool  // Resynchronize the cursor after clearing the screen
+	quote bool  // Insert the next key literally
+	cook  bool  // Read plain lines; input is not a terminal
+
+	handler KeyHandler
 
 	valid bool
 }
 
+// KeyHandler is implemented by applications that bind actions to key events.
+//
+// HandleEvent is called with each key event before the Terminal applies its
+// default line editing behavior. If HandleEvent returns handled=true, the
+// default behavior is skipped, and eol and err are returned to the caller as if
+// the Terminal had processed the event itself.
+type KeyHandler interface {
+	HandleEvent(t *Terminal, ev *key.Event) (handled, eol bool, err error)
+}
+
 // Configure initializes the Terminal configuration.
 func (t *Terminal) Configure(
 	rw io.ReadWriter, prompt []rune, width, height int, flush bool,
 ) *Terminal {
 	t.valid = false
 	t.rw = rw
+	t.output.t = t
+	t.out.Configure(eol.CRLF)
 	t.history.Configure(
 		flush,
 		t.cursor.Configure(
 			flush,
 			t.control.Configure(t,
-				t.in.Configure(eol.LF),
-				t.out.Configure(eol.CRLF)),
+				t.in.Configure(eol.Auto),
+				&t.output),
 			t.display.Configure(width, height, prompt, true),
 		))
 	return t.init()
@@ -57,21 +93,99 @@ func (t *Terminal) Configure(
 func (t *Terminal) init() *Terminal {
 	t.valid = true
 	t.paste = paste.Inactive
+	t.brkt = true
+	t.flow = flow.Resumed
+	t.hold = defaults.FlowTimeout
 	return t
 }
 
 // Swell copies bytes from an input device to the receiver's input buffer.
+//
+// If software flow control is enabled, all XON and XOFF bytes are removed from
+// the input buffer, and output is resumed or paused according to the last one
+// received.
 func (t *Terminal) Swell() (n int, err error) {
 	i, err := io.Copy(&t.in, t.rw)
+	if t.xon {
+		switch t.in.Strip(ascii.DC1, ascii.DC3) {
+		case ascii.DC1: // XON (Ctrl-Q)
+			t.flow = flow.Resumed
+		case ascii.DC3: // XOFF (Ctrl-S)
+			t.flow = flow.Paused
+		}
+	}
 	return int(i), err
 }
 
 // Flush copies bytes from the receiver's output buffer to an output device.
+//
+// If software flow control is enabled and output is paused, Flush retains all
+// bytes in the output buffer and returns n=0.
 func (t *Terminal) Flush() (n int, err error) {
+	if t.xon && t.flow.IsPaused() {
+		return 0, nil
+	}
 	i, err := io.Copy(t.rw, &t.out)
 	return int(i), err
 }
 
+// EnableFlowControl enables or disables software (XON/XOFF) flow control.
+//
+// When enabled, receiving XOFF (Ctrl-S) pauses output until XON (Ctrl-Q) is
+// received. Output written while paused is retained in the output buffer, and
+// the given Policy applies when the output buffer is full.
+func (t *Terminal) EnableFlowControl(enable bool, policy flow.Policy) (wasEnabled bool) {
+	wasEnabled = t.xon
+	t.xon = enable
+	t.block = policy
+	if !enable {
+		t.flow = flow.Resumed
+	}
+	return
+}
+
+// SetFlowTimeout sets the maximum time that output is blocked waiting for XON
+// with policy flow.Block. If timeout is not positive, defaults.FlowTimeout is
+// used.
+//
+// The timeout is enforced as with ProbeSize, i.e., only if reading from the
+// input device returns when no input is available, or if the input device
+// implements SetReadDeadline.
+func (t *Terminal) SetFlowTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = defaults.FlowTimeout
+	}
+	t.hold = timeout
+}
+
+// reserve applies the flow control Policy if output is paused and the output
+// buffer does not have n bytes of free space.
+//
+// With policy flow.Block, ErrTimeout is returned if XON is not received within
+// the flow timeout, and output remains paused.
+func (t *Terminal) reserve(n int) (err error) {
+	if !t.xon || !t.flow.IsPaused() || t.out.Len()+n <= t.out.Cap() {
+		return
+	}
+	switch t.block {
+	case flow.Block:
+		if !t.wait(t.hold, t.resumed) {
+			return &errors.ErrTimeout
+		}
+	case flow.Resume:
+		t.flow = flow.Resumed
+	default:
+		return
+	}
+	_, _ = t.Flush()
+	return
+}
+
+// resumed returns true if and only if output is not paused.
+func (t *Terminal) resumed() bool {
+	return !t.flow.IsPaused()
+}
+
 func (t *Terminal) Cursor() *cursor.Cursor {
 	return &t.cursor
 }
@@ -80,27 +194,276 @@ func (t *Terminal) Line() *line.Line {
 	return t.history.Line()
 }
 
+// SetKeyHandler sets the KeyHandler that receives key events before they are
+// processed by t. If h is nil, all key events are processed by t.
+func (t *Terminal) SetKeyHandler(h KeyHandler) {
+	t.handler = h
+}
+
+// EOL returns the end-of-line sequence conventions of input and output.
+func (t *Terminal) EOL() (in, out eol.Mode) {
+	return t.in.Mode(), t.out.Mode()
+}
+
+// SetEOL sets the end-of-line sequence conventions of input and output.
+//
+// CR and LF always end a line of input. Input mode determines which byte
+// following CR is discarded, so that a device sending CRLF or CR NUL ends a line
+// only once. Output mode determines the sequence written for each end-of-line.
+func (t *Terminal) SetEOL(in, out eol.Mode) {
+	t.in.SetMode(in)
+	t.out.SetMode(out)
+}
+
+// EnableBracketedPaste enables or disables bracketed paste while reading a line.
+// Bracketed paste is enabled by default.
+//
+// When enabled, terminals that support bracketed paste mark the start and end
+// of pasted text, which is then inserted according to the paste Policy instead
+// of being interpreted as keystrokes.
+func (t *Terminal) EnableBracketedPaste(enable bool) (wasEnabled bool) {
+	wasEnabled = t.brkt
+	t.brkt = enable
+	return
+}
+
+// SetPastePolicy sets how text received during a bracketed paste is inserted.
+func (t *Terminal) SetPastePolicy(policy paste.Policy) {
+	t.clip = policy
+}
+
+// EnableMouse enables or disables mouse reporting while reading a line.
+//
+// When enabled, xterm-compatible terminals report mouse clicks and wheel
+// motion, which move the cursor within the line and navigate history,
+// respectively.
+//
+// A click is reported by its screen row, which is mapped to a row of the line
+// relative to the screen row of the line's first row. Unless that row is known,
+// e.g., from Resync, the position of the cursor is requested once the prompt is
+// drawn. Until the reply is received, the line is assumed to end on the last row
+// of the display, where input is read once a terminal has scrolled.
+func (t *Terminal) EnableMouse(enable bool) (wasEnabled bool) {
+	wasEnabled = t.mouse
+	t.mouse = enable
+	return
+}
+
+// EnableHorizontalScroll enables or disables horizontal scrolling.
+//
+// When enabled, the user input line is kept on a single row of the display and
+// scrolled horizontally to keep the cursor visible, instead of wrapping onto
+// multiple rows. Markers are drawn at the left and right edges of the row when
+// text exists beyond the visible portion of the line.
+func (t *Terminal) EnableHorizontalScroll(enable bool) (wasEnabled bool) {
+	wasEnabled = t.display.HorizontalScroll()
+	t.display.SetHorizontalScroll(enable)
+	return
+}
+
+// EnableDumb enables or disables editing for dumb terminals, which do not
+// recognize any control sequences, e.g., printing terminals, serial capture
+// logs, or pipes with TERM=dumb.
+//
+// When enabled, the line is edited using only BS, CR, spaces, and reprinting its
+// text, as done by GNU Readline for dumb terminals. The line is kept on a single
+// row and scrolled horizontally, ClearScreen (Ctrl+L) begins a new row instead
+// of erasing the display, and no styles, bracketed paste, mouse reporting, or
+// queries are written.
+func (t *Terminal) EnableDumb(enable bool) (wasEnabled bool) {
+	wasEnabled = t.display.Dumb()
+	t.display.SetDumb(enable)
+	return
+}
+
+// EnableResync enables or disables resynchronizing the cursor each time the
+// screen is cleared with ClearScreen (Ctrl+L).
+//
+// When enabled, a cursor position report is requested after the prompt is
+// drawn, and the line is redrawn according to the reply as with Resync. The
+// reply is received with other input while reading a line, so no time is spent
+// waiting for terminals that do not reply.
+func (t *Terminal) EnableResync(enable bool) (wasEnabled bool) {
+	wasEnabled = t.sync
+	t.sync = enable
+	return
+}
+
+// Resize sets the size of the display, e.g., when notified by SIGWINCH, telnet
+// NAWS, or ProbeSize, and reflows the prompt and the current line to the new
+// width.
+//
+// The line is erased from the display using the layout of the previous width,
+// and then drawn again with the cursor at the same logical position. Terminals
+// are assumed not to rewrap the rows of the display when resized, as is the case
+// for xterm. For terminals that do, call Resync after Resize.
+//
+// The line is only redrawn while it is being read with ReadLine.
+func (t *Terminal) Resize(width, height int) (err error) {
+	w, h := t.display.Size()
+	t.display.SetSize(width, height)
+	if width, height = t.display.Size(); width == w && height == h {
+		return
+	}
+	if height != h {
+		// The screen row of the current line may have moved.
+		t.cursor.SetOrigin(0)
+	}
+	if !t.display.PromptEnabled() {
+		return
+	}
+	// The cursor's Y coordinate still refers to the previous layout, and the
+	// terminal may have moved the cursor to the last column of the new width.
+	y := t.cursor.Y()
+	if err = t.output.WriteByte(ascii.CR); err != nil {
+		return
+	}
+	if err = t.cursor.Move(y, 0, 0, 0); err != nil {
+		return
+	}
+	_, _ = t.cursor.Set(0, 0)
+	err = t.Line().Redraw()
+	_, _ = t.Flush()
+	return
+}
+
+// SetPrompter sets the Prompter that generates the prompt each time it is
+// drawn, so that it may reflect the current state of the application.
+func (t *Terminal) SetPrompter(p display.Prompter) {
+	t.display.SetPrompter(p)
+}
+
+// EnableSuggestions enables or disables suggestions from the line history.
+//
+// When enabled, the most recent line in history that begins with the text of
+// the current line is suggested, and its remaining text is drawn dimmed after
+// the line. At end of line, Right or End accepts the entire suggestion, and
+// Alt+Right accepts one word of it.
+func (t *Terminal) EnableSuggestions(enable bool) (wasEnabled bool) {
+	wasEnabled = t.display.Suggester() != nil
+	if enable {
+		t.display.SetSuggester(&t.history)
+	} else {
+		t.display.SetSuggester(nil)
+	}
+	return
+}
+
+// SetSuggester sets the Suggester from which suggestions are made, e.g., an
+// application-defined source instead of the line history. If s is nil,
+// suggestions are disabled.
+func (t *Terminal) SetSuggester(s display.Suggester) {
+	t.display.SetSuggester(s)
+}
+
+// SetRightPrompt sets the prompt drawn at the right edge of the first row of the
+// line to a copy of the given prompt, e.g., to show a mode or connection state.
+// The right prompt is hidden while the line extends into the columns it
+// occupies, and otherwise only drawn again when its columns change.
+func (t *Terminal) SetRightPrompt(prompt []rune) {
+	t.display.SetRightPrompt(prompt)
+}
+
+// SetCapabilities sets the optional control functions supported by the
+// terminal, which are used to reduce the number of bytes written when redrawing
+// the line.
+func (t *Terminal) SetCapabilities(caps display.Capability) {
+	t.display.SetCapabilities(caps)
+}
+
+// SetDepth sets the number of colors the terminal is able to display. All styles
+// written by the Terminal are downgraded to depth; see ansi.Style.Downgrade.
+//
+// See display.Discover to determine the depth from the terminal type.
+func (t *Terminal) SetDepth(depth ansi.Depth) {
+	t.display.SetDepth(depth)
+}
+
+// SetHighlighter sets the Highlighter that styles the text of the line as it is
+// typed, and the caller-supplied array of spans it fills each time the line is
+// drawn. The number of spans limits the number of distinctly styled ranges.
+func (t *Terminal) SetHighlighter(h display.Highlighter, spans []display.Span) {
+	t.display.SetHighlighter(h, spans)
+}
+
+// WriteStyle appends the sequence that sets style s, downgraded to the color
+// depth of the terminal, to the output buffer. Nothing is appended for dumb
+// terminals.
+func (t *Terminal) WriteStyle(s ansi.Style) (err error) {
+	if t.display.Dumb() {
+		return
+	}
+	return t.display.SGR().Write(&t.output, s)
+}
+
 func (t *Terminal) ReadLine() (err error) {
+	if t.cook {
+		return t.readCooked()
+	}
 	wasEnabled := t.display.EnablePrompt(true)
 	defer t.display.EnablePrompt(wasEnabled)
+	// Dumb terminals do not recognize the control sequences that enable bracketed
+	// paste and mouse reporting.
+	dumb := t.display.Dumb()
+	if t.brkt && !dumb {
+		_, _ = t.output.Write(ansi.BPE)
+		defer func() {
+			_, _ = t.output.Write(ansi.BPD)
+			_, _ = t.Flush()
+		}()
+	}
+	if t.mouse && !dumb {
+		_, _ = t.output.Write(ansi.MSE)
+		defer func() {
+			_, _ = t.output.Write(ansi.MSD)
+			_, _ = t.Flush()
+		}()
+	}
 	l := t.Line()
 	if err = l.ShowPrompt(); err != nil {
 		return
 	}
+	if t.mouse && !dumb && !t.cursor.HasOrigin() && t.query == queryNone {
+		t.requestOrigin()
+	}
+	if t.paste.IsActive() {
+		// Continue a paste that was split across multiple lines.
+		l.SetIsPasted(true)
+		if t.carry != 0 {
+			err = l.InsertRune(t.carry)
+			t.carry = 0
+		}
+	}
 	eol := false
 	for !eol {
-		for t.in.Len() > 0 {
-			k, sz := t.in.Parse(t.paste.IsActive())
-			if k == key.Unknown {
+		// Stop parsing at the end of the line. Any input that follows is retained
+		// and read by the next call to ReadLine.
+		for !eol && t.in.Len() > 0 {
+			if t.quote {
+				// Insert the next rune received literally, even if it is a control code
+				// or the start of an escape sequence.
+				r, sz := t.in.ParseRune()
+				if sz == 0 {
+					break
+				}
+				eol, err = t.HandleEvent(key.MakeEvent(r))
+				continue
+			}
+			ev, sz := t.in.ParseEvent(t.paste.IsActive())
+			if ev.Code == key.Unknown && t.paste.IsActive() {
+				// Discard escape sequences in pasted text.
+				continue
+			}
+			if ev.Code == key.Unknown {
 				l.MoveCursorTo(l.RuneCount())
-				t.out.WriteEOL()
-				t.cursor.WriteBuf(t.in.Last())
+				t.output.WriteEOL()
+				t.cursor.WriteBuf(ev.Bytes())
 				eol = true
 			} else {
-				if k == key.Error || sz == 0 {
+				if ev.IsError() || sz == 0 {
 					break
 				}
-				eol, err = t.HandleKey(k)
+				eol, err = t.HandleEvent(ev)
 			}
 		}
 		if eol {
@@ -116,22 +479,46 @@ func (t *Terminal) ReadLine() (err error) {
 	return
 }
 
+// HandleKey processes a given key code or rune on the current line.
+//
+// HandleKey is the rune-based equivalent of HandleEvent.
 func (t *Terminal) HandleKey(k rune) (eol bool, err error) {
-	eol, err = t.handleKey(k)
+	return t.HandleEvent(key.MakeEvent(k))
+}
+
+// HandleEvent processes a given key event on the current line.
+// The event is first offered to the KeyHandler, if one was set.
+//
+// If the KeyHandler does not handle the event, the default behavior is applied
+// once for each time the key was pressed (see key.Event.Repeat).
+func (t *Terminal) HandleEvent(ev key.Event) (eol bool, err error) {
+	handled := false
+	if t.handler != nil {
+		handled, eol, err = t.handler.HandleEvent(t, &ev)
+	}
+	for n := ev.Repeat(); !handled && n > 0 && !eol && err == nil; n-- {
+		eol, err = t.handleEvent(&ev)
+	}
 	if eol && t.display.Echo() {
+		t.Line().DismissSuggestion()
 		t.history.Add()
-		t.out.WriteEOL()
+		t.output.WriteEOL()
 	}
 	return
 }
 
-// HandleKey processes a given keypress on the current line.
-func (t *Terminal) handleKey(k rune) (eol bool, err error) {
+// handleEvent applies the default line editing behavior for a given key event.
+func (t *Terminal) handleEvent(ev *key.Event) (eol bool, err error) {
+	k := ev.Key()
 	l := t.Line()
-	// If we are actively pasting, all keys other than Enter and the end-of-paste
-	// sequence should be inserted literally into the line.
-	if t.paste.IsActive() && k != key.Enter && k != key.PasteEnd {
-		return false, l.InsertRune(k)
+	if t.quote {
+		t.quote = false
+		return false, t.insertQuoted(k)
+	}
+	// If we are actively pasting, all keys other than the end-of-paste sequence
+	// are inserted literally into the line according to the paste Policy.
+	if t.paste.IsActive() && k != key.PasteEnd {
+		return t.handlePaste(k)
 	}
 
 	pos := l.Position()
@@ -145,7 +532,7 @@ func (t *Terminal) handleKey(k rune) (eol bool, err error) {
 
 	case key.Backspace:
 		if pos > 0 {
-			l.ErasePreviousRuneCount(1)
+			l.ErasePreviousRuneCount(l.RuneCountToPreviousGrapheme())
 		}
 
 	case key.Interrupt:
@@ -158,8 +545,9 @@ func (t *Terminal) handleKey(k rune) (eol bool, err error) {
 			err = io.EOF
 		} else if pos < siz {
 			// Erase the character under the current position — "rubout".
-			l.MoveCursor(+1)
-			l.ErasePreviousRuneCount(1)
+			n := l.RuneCountToNextGrapheme()
+			l.MoveCursor(+n)
+			l.ErasePreviousRuneCount(n)
 		}
 
 	case key.Up:
@@ -170,12 +558,15 @@ func (t *Terminal) handleKey(k rune) (eol bool, err error) {
 
 	case key.Left:
 		if pos > 0 {
-			l.MoveCursor(-1)
+			l.MoveCursor(-l.RuneCountToPreviousGrapheme())
 		}
 
 	case key.Right:
 		if pos < siz {
-			l.MoveCursor(+1)
+			l.MoveCursor(+l.RuneCountToNextGrapheme())
+		} else {
+			// Accept the entire suggestion at end of line.
+			l.AcceptSuggestion(-1)
 		}
 
 	case key.AltLeft:
@@ -183,8 +574,13 @@ func (t *Terminal) handleKey(k rune) (eol bool, err error) {
 		l.MoveCursor(-l.RuneCountToStartOfWord())
 
 	case key.AltRight:
-		// Move right by 1 word.
-		l.MoveCursor(+l.RuneCountToStartOfNextWord())
+		if pos < siz {
+			// Move right by 1 word.
+			l.MoveCursor(+l.RuneCountToStartOfNextWord())
+		} else {
+			// Accept 1 word of the suggestion at end of line.
+			l.AcceptSuggestion(l.RuneCountToEndOfSuggestedWord())
+		}
 
 	case key.Home:
 		if pos > 0 {
@@ -194,13 +590,16 @@ func (t *Terminal) handleKey(k rune) (eol bool, err error) {
 	case key.End:
 		if pos < siz {
 			l.MoveCursorTo(siz)
+		} else {
+			l.AcceptSuggestion(-1)
 		}
 
 	case key.Delete:
 		if pos < siz {
 			// Erase the character under the current position — "rubout".
-			l.MoveCursor(+1)
-			l.ErasePreviousRuneCount(1)
+			n := l.RuneCountToNextGrapheme()
+			l.MoveCursor(+n)
+			l.ErasePreviousRuneCount(n)
 		}
 
 	case key.DeleteWord:
@@ -222,9 +621,14 @@ func (t *Terminal) handleKey(k rune) (eol bool, err error) {
 		// Erase the screen and move the cursor to the home position.
 		l.ClearScreen()
 		l.ShowPrompt()
+		if t.sync && !t.display.Dumb() {
+			t.requestPosition()
+		}
 
 	case key.PasteStart:
 		t.paste = paste.Active
+		t.from = pos
+		t.full = false
 		if siz == 0 {
 			l.SetIsPasted(true)
 		}
@@ -232,6 +636,30 @@ func (t *Terminal) handleKey(k rune) (eol bool, err error) {
 	case key.PasteEnd:
 		t.paste = paste.Inactive
 
+	case key.MouseLeft:
+		// Move the cursor to the rune that was clicked, if it is on this line.
+		x, y := t.cursor.ScreenToLine(int(ev.X), int(ev.Y))
+		if 0 <= y && y <= t.cursor.MaxY() {
+			l.MoveCursorTo(l.PositionAt(x, y))
+		}
+
+	case key.MouseWheelUp:
+		t.history.Back()
+
+	case key.CursorPosition:
+		t.report(ev)
+
+	case key.QuotedInsert:
+		t.quote = true
+
+	case key.WindowSize:
+		if ev.X > 0 && ev.Y > 0 {
+			err = t.Resize(int(ev.X), int(ev.Y))
+		}
+
+	case key.MouseWheelDown:
+		t.history.Forward()
+
 	default:
 		// if t.AutoCompleteCallback != nil {
 		// 	prefix := string(t.line[:pos])
@@ -259,3 +687,61 @@ func (t *Terminal) handleKey(k rune) (eol bool, err error) {
 	}
 	return
 }
+
+// insertQuoted inserts the key received following key.QuotedInsert literally.
+// C0 control characters are drawn in caret notation (e.g., ^[ for ESC), C1
+// control characters as their code point (e.g., <U+009B> for CSI), and tabs are
+// expanded to the next tab stop. Application-defined key codes, which have
+// no literal representation, are discarded.
+func (t *Terminal) insertQuoted(k rune) (err error) {
+	l := t.Line()
+	if key.IsControl(k) || k == utf8.RuneError || l.RuneCount() >= limits.RunesPerLine {
+		return
+	}
+	l.SetIsPasted(false)
+	return l.InsertRune(k)
+}
+
+// handlePaste inserts a key received during a bracketed paste according to the
+// paste Policy.
+func (t *Terminal) handlePaste(k rune) (eol bool, err error) {
+	if t.paste == paste.Rejected {
+		return
+	}
+	l := t.Line()
+	switch k {
+	case key.Enter:
+		switch t.clip.Newline {
+		case paste.Join:
+			k = ' '
+		case paste.Reject:
+			// Remove everything inserted since the paste started, and discard the
+			// remaining text until the paste ends.
+			t.paste = paste.Rejected
+			err = l.ErasePreviousRuneCount(l.Position() - t.from)
+			_ = t.output.WriteByte(ansi.Alert)
+			return
+		default:
+			l.MoveCursorTo(l.RuneCount())
+			return true, nil
+		}
+	case '\t':
+		k = ' '
+	}
+	if !key.IsPrintable(k) {
+		return
+	}
+	if l.RuneCount() >= limits.RunesPerLine {
+		if t.clip.Overflow == paste.Wrap {
+			t.carry = k
+			l.MoveCursorTo(l.RuneCount())
+			return true, nil
+		}
+		if !t.full {
+			t.full = true
+			_ = t.output.WriteByte(ansi.Alert)
+		}
+		return
+	}
+	return false, l.InsertRune(k)
+}
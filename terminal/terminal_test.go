@@ -0,0 +1,59 @@
+package terminal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/ardnew/embedit/terminal/key"
+)
+
+// text returns the text of the current line of t.
+func text(t *Terminal) string {
+	var sb strings.Builder
+	l := t.Line()
+	for i := l.RuneHead(); i != l.RuneTail(); i++ {
+		sb.WriteRune(l.RuneAt(int(i)).Rune())
+	}
+	return sb.String()
+}
+
+func TestTerminal_HandleEventRepeat(t *testing.T) {
+	t.Parallel()
+	type want struct {
+		text     string
+		position int
+	}
+	for name, tt := range map[string]struct {
+		code  rune
+		count uint16
+		want  want
+	}{
+		"once":            {code: key.Left, want: want{text: "abcd", position: 3}},
+		"count-1":         {code: key.Left, count: 1, want: want{text: "abcd", position: 3}},
+		"count-3":         {code: key.Left, count: 3, want: want{text: "abcd", position: 1}},
+		"beyond-start":    {code: key.Left, count: 9, want: want{text: "abcd", position: 0}},
+		"backspace":       {code: key.Backspace, count: 2, want: want{text: "ab", position: 2}},
+		"backspace-empty": {code: key.Backspace, count: 9, want: want{text: "", position: 0}},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var term Terminal
+			term.Configure(&device{}, []rune("> "), 80, 24, false)
+			for _, r := range "abcd" {
+				_, _ = term.HandleKey(r)
+			}
+			ev := key.MakeEvent(tt.code)
+			ev.Count = tt.count
+			if _, err := term.HandleEvent(ev); err != nil {
+				t.Fatalf("HandleEvent() error = %v", err)
+			}
+			got := want{text: text(&term), position: term.Line().Position()}
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("HandleEvent() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
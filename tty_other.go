@@ -0,0 +1,12 @@
+//go:build !(linux || darwin || freebsd) || baremetal
+// +build !linux,!darwin,!freebsd baremetal
+
+package embedit
+
+import "io"
+
+// isTerminal returns true, since file descriptors are not supported on this
+// target. Use Config.Cooked to read plain lines.
+func isTerminal(rw io.ReadWriter) bool {
+	return true
+}
@@ -0,0 +1,25 @@
+//go:build (linux || darwin || freebsd) && !baremetal
+// +build linux darwin freebsd
+// +build !baremetal
+
+package embedit
+
+import (
+	"io"
+
+	"github.com/ardnew/embedit/sys"
+)
+
+// fder is implemented by an RW with a file descriptor, e.g., *os.File.
+type fder interface {
+	Fd() uintptr
+}
+
+// isTerminal returns false if and only if rw has a file descriptor, e.g., an
+// *os.File, and that file descriptor is not connected to a terminal.
+func isTerminal(rw io.ReadWriter) bool {
+	if f, ok := rw.(fder); ok {
+		return sys.IsTerminal(int(f.Fd()))
+	}
+	return true
+}